@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"github.com/LeMikaelF/proxy-generator/tests"
+	"github.com/LeMikaelF/proxy-generator/tests/hooks"
+	"github.com/LeMikaelF/proxy-generator/tests/middleware"
+	"testing"
+)
+
+var benchmarkErr error
+
+// BenchmarkProxy compares calling the delegate directly with calling it through the proxy, either directly for a
+// passthrough method, through the default invocation handler, through typed middleware, or through typed hooks.
+func BenchmarkProxy(b *testing.B) {
+	service := tests.NewMyService("a", "b")
+	proxy := tests.NewMyServiceProxy(service, nil)
+	hooksProxy := hooks.NewMyServiceProxy(service, hooks.MyServiceHooks{
+		BeforeTwoArgsErrorMethod: func(ctx context.Context, aStruct tests.Struct) (context.Context, tests.Struct, error) {
+			return ctx, aStruct, nil
+		},
+		AfterTwoArgsErrorMethod: func(ctx context.Context, aStruct tests.Struct, result string, err error) (string, error) {
+			return result, err
+		},
+	})
+	middlewareProxy := middleware.NewMyServiceProxy(service, nil, middleware.WithMyServiceTwoArgsErrorMethodMiddleware(
+		func(next middleware.MyServiceTwoArgsErrorMethodFunc) middleware.MyServiceTwoArgsErrorMethodFunc {
+			return next
+		},
+	))
+	ctx := context.Background()
+
+	b.Run("Direct", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			benchmarkErr = service.OneArgErrorMethod()
+		}
+	})
+
+	b.Run("Passthrough", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			benchmarkErr = proxy.PassthroughMethod()
+		}
+	})
+
+	b.Run("Intercepted", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			benchmarkErr = proxy.OneArgErrorMethod()
+		}
+	})
+
+	b.Run("InterceptedWithArgs", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_, benchmarkErr = proxy.TwoArgsErrorMethod(ctx, tests.Struct{})
+		}
+	})
+
+	b.Run("Middleware", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_, benchmarkErr = middlewareProxy.TwoArgsErrorMethod(ctx, tests.Struct{})
+		}
+	})
+
+	b.Run("Hooks", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_, benchmarkErr = hooksProxy.TwoArgsErrorMethod(ctx, tests.Struct{})
+		}
+	})
+}
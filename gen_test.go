@@ -2,8 +2,13 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"github.com/LeMikaelF/proxy-generator/tests"
+	"github.com/LeMikaelF/proxy-generator/tests/hooks"
+	"github.com/LeMikaelF/proxy-generator/tests/middleware"
+	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -43,3 +48,190 @@ func Test_MyStuff(_ *testing.T) {
 	proxy.NoArgsMethod()
 	proxy.ContextMethod(context.Background())
 }
+
+func Test_NilAndInvalidResults(t *testing.T) {
+	service := tests.NewMyService("a", "b")
+	var handlerResults []any
+	invocationHandler := func(method interface {
+		Package() string
+		Receiver() string
+		Name() string
+		Invoke(args []any) []any
+	}, args []any) (retVals []any) {
+		return handlerResults
+	}
+
+	proxy := tests.NewMyServiceProxy(service, invocationHandler)
+
+	handlerResults = []any{"result", nil}
+	result, err := proxy.TwoArgsErrorMethod(context.Background(), tests.Struct{})
+	if result != "result" || err != nil {
+		t.Errorf("Expected (result, nil), got (%s, %v)", result, err)
+	}
+
+	handlerResults = []any{nil, nil}
+	result, err = proxy.TwoArgsErrorMethod(context.Background(), tests.Struct{})
+	if result != "" || err != nil {
+		t.Errorf("Expected zero values, got (%s, %v)", result, err)
+	}
+
+	assertPanics(t, "invocation handler returned 1 results for MyService.TwoArgsErrorMethod, expected 2", func() {
+		handlerResults = []any{"result"}
+		_, _ = proxy.TwoArgsErrorMethod(context.Background(), tests.Struct{})
+	})
+
+	assertPanics(t, "invocation handler returned int at index 0 for MyService.TwoArgsErrorMethod, expected string", func() {
+		handlerResults = []any{1, nil}
+		_, _ = proxy.TwoArgsErrorMethod(context.Background(), tests.Struct{})
+	})
+}
+
+func Test_SignatureMetadata(t *testing.T) {
+	type signature interface {
+		ParamNames() []string
+		ParamTypes() []string
+		ResultTypes() []string
+		Variadic() bool
+		ErrorIndex() int
+		ContextIndex() int
+		Position() string
+	}
+
+	var got signature
+	invocationHandler := func(method interface {
+		Package() string
+		Receiver() string
+		Name() string
+		Invoke(args []any) []any
+	}, args []any) (retVals []any) {
+		got, _ = method.(signature)
+		return method.Invoke(args)
+	}
+
+	proxy := tests.NewMyServiceProxy(tests.NewMyService("a", "b"), invocationHandler)
+	_, _ = proxy.TwoArgsErrorMethod(context.Background(), tests.Struct{})
+
+	if got == nil {
+		t.Fatal("Expected the method to describe its signature")
+	}
+	if !reflect.DeepEqual(got.ParamNames(), []string{"ctx", "aStruct"}) || !reflect.DeepEqual(got.ParamTypes(), []string{"context.Context", "Struct"}) {
+		t.Errorf("Unexpected parameters %v %v", got.ParamNames(), got.ParamTypes())
+	}
+	// Descriptors are shared by every call, so handlers modifying them must not affect later calls.
+	got.ParamNames()[0] = "modified"
+	if got.ParamNames()[0] != "ctx" {
+		t.Errorf("Expected parameter names to be copied, got %v", got.ParamNames())
+	}
+	if !reflect.DeepEqual(got.ResultTypes(), []string{"string", "error"}) || got.Variadic() {
+		t.Errorf("Unexpected results %v, variadic %v", got.ResultTypes(), got.Variadic())
+	}
+	if got.ErrorIndex() != 1 || got.ContextIndex() != 0 {
+		t.Errorf("Expected error index 1 and context index 0, got %d and %d", got.ErrorIndex(), got.ContextIndex())
+	}
+	if !strings.HasPrefix(got.Position(), "myservice.go:") {
+		t.Errorf("Expected position in myservice.go, got '%s'", got.Position())
+	}
+}
+
+func Test_Hooks(t *testing.T) {
+	type key struct{}
+	errRejected := errors.New("rejected")
+
+	var afterCtx context.Context
+	proxy := hooks.NewMyServiceProxy(tests.NewMyService("a", "b"), hooks.MyServiceHooks{
+		BeforeTwoArgsErrorMethod: func(ctx context.Context, aStruct tests.Struct) (context.Context, tests.Struct, error) {
+			if ctx.Value(key{}) == "reject" {
+				return ctx, aStruct, errRejected
+			}
+			return context.WithValue(ctx, key{}, "before"), aStruct, nil
+		},
+		AfterTwoArgsErrorMethod: func(ctx context.Context, aStruct tests.Struct, result string, err error) (string, error) {
+			afterCtx = ctx
+			return "recovered from " + err.Error(), nil
+		},
+	})
+
+	result, err := proxy.TwoArgsErrorMethod(context.Background(), tests.Struct{})
+	if result != "recovered from grosse erreur" || err != nil {
+		t.Errorf("Expected results replaced by the after hook, got (%s, %v)", result, err)
+	}
+	if afterCtx == nil || afterCtx.Value(key{}) != "before" {
+		t.Errorf("Expected the after hook to receive the arguments replaced by the before hook")
+	}
+
+	afterCtx = nil
+	result, err = proxy.TwoArgsErrorMethod(context.WithValue(context.Background(), key{}, "reject"), tests.Struct{})
+	if result != "" || err != errRejected {
+		t.Errorf("Expected the error of the before hook, got (%s, %v)", result, err)
+	}
+	if afterCtx != nil {
+		t.Errorf("Expected the after hook to be skipped when the before hook fails")
+	}
+
+	// Methods without hooks call the delegate directly.
+	if err := proxy.OneArgErrorMethod(); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+}
+
+func Test_Middleware(t *testing.T) {
+	var calls []string
+	trace := func(name string) middleware.MyServiceTwoArgsErrorMethodMiddleware {
+		return func(next middleware.MyServiceTwoArgsErrorMethodFunc) middleware.MyServiceTwoArgsErrorMethodFunc {
+			return func(ctx context.Context, aStruct tests.Struct) (string, error) {
+				calls = append(calls, name)
+				return next(ctx, aStruct)
+			}
+		}
+	}
+	retry := func(next middleware.MyServiceTwoArgsErrorMethodFunc) middleware.MyServiceTwoArgsErrorMethodFunc {
+		return func(ctx context.Context, aStruct tests.Struct) (string, error) {
+			result, err := next(ctx, aStruct)
+			if err != nil {
+				return next(ctx, aStruct)
+			}
+			return result, err
+		}
+	}
+	invocationHandler := func(method interface {
+		Package() string
+		Receiver() string
+		Name() string
+		Invoke(args []any) []any
+	}, args []any) []any {
+		calls = append(calls, "handler "+method.Name())
+		return method.Invoke(args)
+	}
+
+	proxy := middleware.NewMyServiceProxy(tests.NewMyService("a", "b"), invocationHandler,
+		middleware.WithMyServiceTwoArgsErrorMethodMiddleware(trace("outer"), retry),
+		middleware.WithMyServiceTwoArgsErrorMethodMiddleware(trace("inner")))
+
+	_, err := proxy.TwoArgsErrorMethod(context.Background(), tests.Struct{})
+	if err == nil || err.Error() != "grosse erreur" {
+		t.Errorf("Expected the error of the delegate, got %v", err)
+	}
+	expected := []string{"outer", "inner", "handler TwoArgsErrorMethod", "inner", "handler TwoArgsErrorMethod"}
+	if !reflect.DeepEqual(calls, expected) {
+		t.Errorf("Expected calls %v, got %v", expected, calls)
+	}
+
+	// Methods without middleware call the invocation handler directly.
+	calls = nil
+	if err := proxy.OneArgErrorMethod(); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	if expected := []string{"handler OneArgErrorMethod"}; !reflect.DeepEqual(calls, expected) {
+		t.Errorf("Expected calls %v, got %v", expected, calls)
+	}
+}
+
+func assertPanics(t *testing.T, expected string, f func()) {
+	t.Helper()
+	defer func() {
+		if r := recover(); r != expected {
+			t.Errorf("Expected panic %q, got %v", expected, r)
+		}
+	}()
+	f()
+}
@@ -0,0 +1,522 @@
+package generator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/LeMikaelF/proxy-generator/generator/internal/diag"
+	"github.com/LeMikaelF/proxy-generator/generator/internal/source"
+	"github.com/LeMikaelF/proxy-generator/generator/internal/tmpl"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/scanner"
+	"go/token"
+	"go/types"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Diagnostic describes a problem preventing generation, such as a method whose signature can't be resolved, located in
+// the source when possible.
+type Diagnostic = diag.Diagnostic
+
+// Diagnostics is the error returned by Generate when the source of the proxied types prevents generating their
+// proxies. It holds every problem found, rather than only the first one.
+type Diagnostics = diag.List
+
+// Options configures the proxies generated by Generate.
+type Options struct {
+	// Dir holds the source files of the package declaring the proxied types, at its root.
+	Dir fs.FS
+	// PackageName is the name of the package declaring the proxied types. Files of other packages in Dir, such as
+	// external test packages, are ignored.
+	PackageName string
+	// ImportPath is the import path of the package declaring the proxied types. It's only needed with OutputPackage.
+	ImportPath string
+	// TypeNames lists the types to proxy, which may contain wildcards, such as *Service, to match several types.
+	TypeNames []string
+	// PassthroughMethods holds the names of the methods which call the delegate directly, without calling the
+	// invocation handler.
+	PassthroughMethods map[string]bool
+	// IncludeUnexported also proxies unexported methods, for proxies used within the package of the proxied type.
+	IncludeUnexported bool
+	// DelegateKind is value, pointer or auto, to hold delegates by value when all their methods have value receivers.
+	// It defaults to pointer.
+	DelegateKind string
+	// Implements lists interfaces that the proxies must implement, such as io.Reader or example.com/pkg.Interface.
+	Implements []string
+	// EmitInterface, if not empty, is the name of an interface to generate with the proxied methods.
+	EmitInterface string
+	// MethodTags holds tags of methods by Type.Method, which invocation handlers can read from the method descriptor.
+	// Tags from //proxy:tag directives take precedence.
+	MethodTags map[string]map[string]string
+	// Mode is handler, to generate proxies calling an invocation handler with the arguments as []any, or hooks, to
+	// generate proxies calling typed hook funcs around each method. It defaults to handler.
+	Mode string
+	// Middleware generates typed middleware for each method, which the proxy calls before the invocation handler. It
+	// isn't available in hooks mode.
+	Middleware bool
+	// Combine generates all the proxies in a single file.
+	Combine bool
+	// Output is the name of the generated file. It defaults to <Type>_proxy_gen.go, or proxy_gen.go when combined.
+	Output string
+	// OutputPackage is the import path of the package to generate the proxies in, if not the package of the proxied
+	// types.
+	OutputPackage string
+	// OutputPackageName is the name of the package to generate the proxies in, with OutputPackage. It defaults to the
+	// last element of its import path, without a major version suffix such as v2.
+	OutputPackageName string
+}
+
+// Generate renders the proxies described by options, returning the content of each generated file by name.
+//
+// The packages imported by the proxied package are type-checked from source, and resolved from the module of the
+// current working directory.
+func Generate(ctx context.Context, options Options) (map[string][]byte, error) {
+	if options.Dir == nil {
+		return nil, errors.New("no directory to read the package from")
+	}
+	if len(options.TypeNames) == 0 {
+		return nil, errors.New("no types to proxy")
+	}
+
+	delegateKind := source.DelegateKind(options.DelegateKind)
+	switch delegateKind {
+	case "":
+		delegateKind = source.DelegatePointer
+	case source.DelegatePointer, source.DelegateValue, source.DelegateAuto:
+	default:
+		return nil, fmt.Errorf("invalid delegate kind %q, expected value, pointer or auto", options.DelegateKind)
+	}
+
+	switch options.Mode {
+	case "", "handler", "hooks":
+	default:
+		return nil, fmt.Errorf("invalid mode %q, expected handler or hooks", options.Mode)
+	}
+	if options.Middleware && options.Mode == "hooks" {
+		return nil, errors.New("middleware isn't available in hooks mode")
+	}
+
+	importPath := options.ImportPath
+	if importPath == "" {
+		if options.OutputPackage != "" {
+			return nil, fmt.Errorf("the import path of package %s is needed to import it from %s", options.PackageName, options.OutputPackage)
+		}
+		importPath = options.PackageName
+	}
+
+	fset := token.NewFileSet()
+	fileNodes, err := parseDir(fset, options.Dir, options.PackageName)
+	if err != nil {
+		return nil, err
+	}
+
+	pkg, err := source.Load(importPath, fset, fileNodes, importer.ForCompiler(fset, "source", nil))
+	if err != nil {
+		return nil, err
+	}
+
+	typeNames, err := pkg.TypeNames(options.TypeNames)
+	if err != nil {
+		return nil, err
+	}
+
+	if options.EmitInterface != "" && len(typeNames) > 1 {
+		return nil, fmt.Errorf("cannot emit interface %s for several types", options.EmitInterface)
+	}
+
+	if options.Output != "" && !options.Combine && len(typeNames) > 1 {
+		return nil, fmt.Errorf("cannot write several proxies to %s without --combine", options.Output)
+	}
+
+	packageName := pkg.Name()
+	if options.OutputPackage != "" {
+		if packageName, err = outputPackageName(options); err != nil {
+			return nil, err
+		}
+	}
+
+	// The problems of every type are reported together, rather than only those of the first one.
+	var diagnostics diag.List
+	diagnostics = append(diagnostics, unknownTagTypes(options.MethodTags, typeNames)...)
+	g := &generation{
+		options:      options,
+		delegateKind: delegateKind,
+		pkg:          pkg,
+		files:        make(map[string][]byte),
+		declarations: make(map[string]string),
+		replaced:     make(map[string]bool),
+	}
+	// The files generated again are replaced, so their declarations don't clash with the new ones.
+	if options.Combine {
+		g.replaced[path.Clean(filepath.ToSlash(g.outputFile("proxy_gen.go")))] = true
+	} else {
+		for _, typeName := range typeNames {
+			g.replaced[path.Clean(filepath.ToSlash(g.outputFile(fmt.Sprintf("%s_proxy_gen.go", typeName))))] = true
+		}
+	}
+
+	if options.Combine {
+		imports := g.newImports()
+		var proxies []tmpl.Proxy
+		for _, typeName := range typeNames {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			proxy, err := g.proxy(typeName, imports)
+			if err != nil {
+				diagnostics.Append(err)
+				continue
+			}
+			proxies = append(proxies, proxy)
+		}
+		if err := diagnostics.Err(); err != nil {
+			return nil, err
+		}
+		fileName := g.outputFile("proxy_gen.go")
+		helpers := combinedHelpers(fileName)
+		if len(proxies) == 1 {
+			helpers = proxies[0].Type.Name
+		}
+		if err := g.declareHelpers(fileName, helpers, proxies...); err != nil {
+			return nil, err
+		}
+		if err := g.render(fileName, tmpl.New(packageName, pkg.Name(), helpers, imports.Specs(), proxies...)); err != nil {
+			return nil, err
+		}
+		return g.files, nil
+	}
+
+	for _, typeName := range typeNames {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		imports := g.newImports()
+		proxy, err := g.proxy(typeName, imports)
+		if err != nil {
+			diagnostics.Append(err)
+			continue
+		}
+
+		generatedFileName := g.outputFile(fmt.Sprintf("%s_proxy_gen.go", typeName))
+		if err := g.declareHelpers(generatedFileName, typeName, proxy); err != nil {
+			diagnostics.Append(err)
+			continue
+		}
+		if err := g.render(generatedFileName, tmpl.New(packageName, pkg.Name(), typeName, imports.Specs(), proxy)); err != nil {
+			diagnostics.Append(err)
+		}
+	}
+
+	if err := diagnostics.Err(); err != nil {
+		return nil, err
+	}
+	return g.files, nil
+}
+
+var majorVersion = regexp.MustCompile(`^v[0-9]+$`)
+
+// outputPackageName returns the name of the package to generate the proxies in, when it's another package. Unless
+// it's given, it's derived from the import path, with the characters which can't appear in an identifier, such as
+// hyphens, replaced by underscores.
+func outputPackageName(options Options) (string, error) {
+	name := options.OutputPackageName
+	if name == "" {
+		name = path.Base(options.OutputPackage)
+		if majorVersion.MatchString(name) && path.Dir(options.OutputPackage) != "." {
+			name = path.Base(path.Dir(options.OutputPackage))
+		}
+		name = strings.Map(func(r rune) rune {
+			if r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r) {
+				return r
+			}
+			return '_'
+		}, name)
+	}
+
+	if !token.IsIdentifier(name) || name == "_" {
+		return "", fmt.Errorf("invalid package name %q for %s", name, options.OutputPackage)
+	}
+	return name, nil
+}
+
+// parseDir parses the files of package packageName at the root of dir.
+func parseDir(fset *token.FileSet, dir fs.FS, packageName string) ([]*ast.File, error) {
+	files, err := fs.Glob(dir, "*.go")
+	if err != nil {
+		return nil, fmt.Errorf("error finding go files: %v", err)
+	}
+
+	var fileNodes []*ast.File
+	var diagnostics diag.List
+	for _, file := range files {
+		// Test files aren't part of the package's build, and may declare conflicting test helpers.
+		if strings.HasSuffix(file, "_test.go") {
+			continue
+		}
+
+		fileData, err := fs.ReadFile(dir, file)
+		if err != nil {
+			return nil, fmt.Errorf("error reading file %s: %v", file, err)
+		}
+		fileNode, err := parser.ParseFile(fset, file, fileData, parser.AllErrors|parser.ParseComments)
+		if errorList, ok := err.(scanner.ErrorList); ok {
+			for _, e := range errorList {
+				diagnostics = append(diagnostics, diag.New(e.Pos, "", e.Msg))
+			}
+			continue
+		} else if err != nil {
+			return nil, fmt.Errorf("error parsing file %s: %v", file, err)
+		}
+
+		if fileNode.Name.Name != packageName {
+			continue
+		}
+		fileNodes = append(fileNodes, fileNode)
+	}
+
+	// Parse errors are reported once every file is parsed, so that all of them are reported together.
+	if err := diagnostics.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(fileNodes) == 0 {
+		return nil, fmt.Errorf("could not find go files for package %s", packageName)
+	}
+
+	return fileNodes, nil
+}
+
+// generation holds the state of a call to Generate.
+type generation struct {
+	options      Options
+	delegateKind source.DelegateKind
+	pkg          *source.Package
+	files        map[string][]byte
+	// declarations holds what declares each of the declarations generated so far, as in helpers of proxy_gen.go, since
+	// the proxies of the package share its scope.
+	declarations map[string]string
+	// replaced holds the files generated again, by their slash-separated path.
+	replaced map[string]bool
+}
+
+func (g *generation) newImports() *source.Imports {
+	imports := g.pkg.NewImports()
+	if g.options.OutputPackage != "" {
+		imports = g.pkg.NewExternalImports(g.options.OutputPackage)
+	}
+	// The helpers of invocation handlers use fmt, so it must not be shadowed by another package with the same name.
+	if g.options.Mode != "hooks" {
+		imports.Qualifier(types.NewPackage("fmt", "fmt"))
+	}
+	return imports
+}
+
+// proxy describes the proxy of the type named typeName, adding the packages it references to imports.
+func (g *generation) proxy(typeName string, imports *source.Imports) (tmpl.Proxy, error) {
+	tags := make(map[string]map[string]string)
+	for name, methodTags := range g.options.MethodTags {
+		if methodName, ok := strings.CutPrefix(name, typeName+"."); ok {
+			tags[methodName] = methodTags
+		}
+	}
+
+	proxiedType, err := g.pkg.FindType(typeName, source.Options{
+		PassthroughMethods: g.options.PassthroughMethods,
+		IncludeUnexported:  g.options.IncludeUnexported,
+		DelegateKind:       g.delegateKind,
+		Tags:               tags,
+	}, imports)
+	if err != nil {
+		return tmpl.Proxy{}, err
+	}
+
+	// Tags of methods which aren't proxied are likely typos, or left behind by a rename.
+	var diagnostics diag.List
+	for _, methodName := range sortedKeys(tags) {
+		if !proxiedType.HasMethod(methodName) {
+			diagnostics = append(diagnostics, Diagnostic{Method: methodName, Message: fmt.Sprintf("tags refer to method %s.%s, which isn't proxied", typeName, methodName)})
+		}
+	}
+	if err := diagnostics.Err(); err != nil {
+		return tmpl.Proxy{}, err
+	}
+
+	var implements []string
+	for _, name := range g.options.Implements {
+		iface, err := g.pkg.CheckImplements(proxiedType, name, imports)
+		if err != nil {
+			return tmpl.Proxy{}, err
+		}
+		implements = append(implements, iface)
+	}
+
+	if g.options.EmitInterface != "" && proxiedType.TypeParams != "" {
+		return tmpl.Proxy{}, fmt.Errorf("cannot emit an interface for generic type %s", typeName)
+	}
+
+	// The methods of generic types may name the type parameters differently, which the types of hooks and middleware,
+	// declared once for all the methods, can't reconcile.
+	hooks := g.options.Mode == "hooks"
+	if hooks && proxiedType.TypeParams != "" {
+		return tmpl.Proxy{}, fmt.Errorf("cannot generate hooks for generic type %s", typeName)
+	}
+	if g.options.Middleware && proxiedType.TypeParams != "" {
+		return tmpl.Proxy{}, fmt.Errorf("cannot generate middleware for generic type %s", typeName)
+	}
+
+	if g.options.Middleware {
+		if err := g.declareMiddleware(proxiedType); err != nil {
+			return tmpl.Proxy{}, err
+		}
+	}
+
+	return tmpl.Proxy{
+		Type:          proxiedType,
+		Implements:    implements,
+		InterfaceName: g.options.EmitInterface,
+		Hooks:         hooks,
+		Middleware:    g.options.Middleware,
+	}, nil
+}
+
+// declareMiddleware reserves the exported declarations of the middleware of t, reporting those which clash, as
+// MyServiceGetFunc would for both MyService.Get and My.ServiceGet.
+func (g *generation) declareMiddleware(t *source.Type) error {
+	// The method declaring each name, if any.
+	names := map[string]string{t.Name + "ProxyOption": ""}
+	for _, m := range t.Methods {
+		if !m.Passthrough {
+			names[t.Name+m.Name+"Func"] = m.Name
+			names[t.Name+m.Name+"Middleware"] = m.Name
+			names["With"+t.Name+m.Name+"Middleware"] = m.Name
+		}
+	}
+	return g.declare(names, "middleware of "+t.Name)
+}
+
+// combinedHelpers names the helper declarations shared by the proxies of a combined file after the file, as in ProxyGen
+// for proxy_gen.go, so that each combined file of a package declares its own.
+func combinedHelpers(fileName string) string {
+	if fileName == "-" {
+		return "Proxy"
+	}
+
+	var b strings.Builder
+	upper := true
+	for _, r := range strings.TrimSuffix(filepath.Base(fileName), ".go") {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// declareHelpers reserves the helper declarations of fileName, named after helpers, which the proxies of the file need.
+func (g *generation) declareHelpers(fileName string, helpers string, proxies ...tmpl.Proxy) error {
+	names := make(map[string]string)
+	for _, proxy := range proxies {
+		if !proxy.Hooks {
+			for _, suffix := range []string{"Method", "CheckResults", "Result"} {
+				names["_"+helpers+suffix] = ""
+			}
+		}
+		if proxy.Middleware {
+			names["_"+helpers+"Chain"] = ""
+		}
+	}
+	return g.declare(names, "helpers of "+fileName)
+}
+
+// declare reserves names for owner, such as the helpers of a file, reporting those which clash with a declaration of
+// another owner, or of the package outside the files generated again. names holds the method declaring each name, if
+// any.
+func (g *generation) declare(names map[string]string, owner string) error {
+	var diagnostics diag.List
+	for _, name := range sortedKeys(names) {
+		if other, ok := g.declarations[name]; ok {
+			diagnostics = append(diagnostics, Diagnostic{Method: names[name], Message: fmt.Sprintf("%s of the %s conflicts with that of the %s", name, owner, other)})
+		} else if file, ok := g.declaredIn(name); ok {
+			diagnostics = append(diagnostics, Diagnostic{Method: names[name], Message: fmt.Sprintf("%s of the %s conflicts with a declaration of package %s in %s", name, owner, g.pkg.Name(), file)})
+		}
+	}
+	if err := diagnostics.Err(); err != nil {
+		return err
+	}
+
+	for name := range names {
+		g.declarations[name] = owner
+	}
+	return nil
+}
+
+// declaredIn returns the file declaring name in the package of the proxies, outside the files generated again. The
+// declarations of another package aren't known, but only the proxies of this run are generated in it.
+func (g *generation) declaredIn(name string) (string, bool) {
+	if g.options.OutputPackage != "" {
+		return "", false
+	}
+	file, ok := g.pkg.Declaration(name)
+	if !ok || g.replaced[path.Clean(filepath.ToSlash(file))] {
+		return "", false
+	}
+	return file, true
+}
+
+// unknownTagTypes reports the tags of types which aren't proxied, which are likely typos, as those of methods are.
+func unknownTagTypes(methodTags map[string]map[string]string, typeNames []string) diag.List {
+	proxied := make(map[string]bool, len(typeNames))
+	for _, typeName := range typeNames {
+		proxied[typeName] = true
+	}
+
+	var diagnostics diag.List
+	for _, name := range sortedKeys(methodTags) {
+		typeName, methodName, ok := strings.Cut(name, ".")
+		if !ok || typeName == "" || methodName == "" {
+			diagnostics = append(diagnostics, Diagnostic{Message: fmt.Sprintf("malformed tags key %q, expected Type.Method", name)})
+		} else if !proxied[typeName] {
+			diagnostics = append(diagnostics, Diagnostic{Method: methodName, Message: fmt.Sprintf("tags refer to method %s, whose type %s isn't proxied", name, typeName)})
+		}
+	}
+	return diagnostics
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// outputFile returns the file to write generated code to, unless another one was requested.
+func (g *generation) outputFile(defaultName string) string {
+	if g.options.Output != "" {
+		return g.options.Output
+	}
+	return defaultName
+}
+
+func (g *generation) render(fileName string, template *tmpl.Template) error {
+	generatedCode, err := template.Render()
+	if err != nil {
+		return err
+	}
+	g.files[fileName] = generatedCode
+	return nil
+}
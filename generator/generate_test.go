@@ -0,0 +1,412 @@
+package generator
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestGenerate(t *testing.T) {
+	dir := fstest.MapFS{
+		"service.go": &fstest.MapFile{Data: []byte(`package svc
+
+type Service struct{}
+
+func (s *Service) Get(id string) (string, error) { return id, nil }
+`)},
+		"service_test.go": &fstest.MapFile{Data: []byte(`package svc
+
+func helper() {}
+`)},
+		"other.go": &fstest.MapFile{Data: []byte(`package other
+`)},
+	}
+
+	files, err := Generate(context.Background(), Options{
+		Dir:         dir,
+		PackageName: "svc",
+		TypeNames:   []string{"Service"},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(files) != 1 {
+		t.Fatalf("Expected a single file, got %d", len(files))
+	}
+	output := string(files["Service_proxy_gen.go"])
+	if !strings.HasPrefix(output, "package svc\n") || !strings.Contains(output, "func (d *ServiceProxy) Get(id string) (string, error)") {
+		t.Errorf("Unexpected output:\n%s", output)
+	}
+
+	typeCheck(t, map[string][]byte{"service.go": dir["service.go"].Data, "Service_proxy_gen.go": files["Service_proxy_gen.go"]})
+}
+
+func TestGenerate_Tags(t *testing.T) {
+	dir := fstest.MapFS{
+		"service.go": &fstest.MapFile{Data: []byte(`package svc
+
+type Service struct{}
+
+//proxy:tag cache=true
+func (s *Service) Get(id string) string { return id }
+
+func (s *Service) Put(id string) {}
+
+func (s *Service) Delete(id string) {}
+`)},
+	}
+
+	files, err := Generate(context.Background(), Options{
+		Dir:         dir,
+		PackageName: "svc",
+		TypeNames:   []string{"Service"},
+		MethodTags: map[string]map[string]string{
+			"Service.Get": {"cache": "false", "audit": "full"},
+			"Service.Put": {"audit": "full"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	output := string(files["Service_proxy_gen.go"])
+	for _, expected := range []string{
+		`map[string]string{"audit": "full", "cache": "true"},`,
+		`map[string]string{"audit": "full"},`,
+	} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("Expected output to contain %s, got:\n%s", expected, output)
+		}
+	}
+	if strings.Count(output, "tags:") != 2 {
+		t.Errorf("Expected tags only for tagged methods, got:\n%s", output)
+	}
+
+	typeCheck(t, map[string][]byte{"service.go": dir["service.go"].Data, "Service_proxy_gen.go": files["Service_proxy_gen.go"]})
+
+	_, err = Generate(context.Background(), Options{
+		Dir:         dir,
+		PackageName: "svc",
+		TypeNames:   []string{"Service"},
+		MethodTags: map[string]map[string]string{
+			"Service.Fetch": {"cache": "true"},
+			"Other.Put":     {"audit": "none"},
+			"Put":           {"audit": "none"},
+		},
+	})
+	expected := Diagnostics{
+		{Method: "Put", Message: "tags refer to method Other.Put, whose type Other isn't proxied"},
+		{Message: `malformed tags key "Put", expected Type.Method`},
+		{Method: "Fetch", Message: "tags refer to method Service.Fetch, which isn't proxied"},
+	}
+	if diagnostics, ok := err.(Diagnostics); !ok || !reflect.DeepEqual(diagnostics, expected) {
+		t.Errorf("Expected diagnostics %v, got %v", expected, err)
+	}
+}
+
+func TestGenerate_MiddlewareCollisions(t *testing.T) {
+	dir := fstest.MapFS{
+		"service.go": &fstest.MapFile{Data: []byte(`package svc
+
+type My struct{}
+
+func (m *My) ServiceGet() {}
+
+type MyService struct{}
+
+func (s *MyService) Get() {}
+
+type Store struct{}
+
+func (s *Store) Put() {}
+`)},
+		"Other_proxy_gen.go": &fstest.MapFile{Data: []byte(`// Code generated by Mikaël's proxy generator. DO NOT EDIT.
+
+package svc
+
+type StorePutFunc func()
+`)},
+		"Store_proxy_gen.go": &fstest.MapFile{Data: []byte(`// Code generated by Mikaël's proxy generator. DO NOT EDIT.
+
+package svc
+
+type StorePutMiddleware func(next StorePutFunc) StorePutFunc
+`)},
+	}
+
+	// Store_proxy_gen.go is generated again, unlike Other_proxy_gen.go.
+	_, err := Generate(context.Background(), Options{
+		Dir:         dir,
+		PackageName: "svc",
+		TypeNames:   []string{"My", "MyService", "Store"},
+		Middleware:  true,
+	})
+
+	expected := Diagnostics{
+		{Method: "Get", Message: "MyServiceGetFunc of the middleware of MyService conflicts with that of the middleware of My"},
+		{Method: "Get", Message: "MyServiceGetMiddleware of the middleware of MyService conflicts with that of the middleware of My"},
+		{Method: "Get", Message: "WithMyServiceGetMiddleware of the middleware of MyService conflicts with that of the middleware of My"},
+		{Method: "Put", Message: "StorePutFunc of the middleware of Store conflicts with a declaration of package svc in Other_proxy_gen.go"},
+	}
+	if diagnostics, ok := err.(Diagnostics); !ok || !reflect.DeepEqual(diagnostics, expected) {
+		t.Errorf("Expected diagnostics %v, got %v", expected, err)
+	}
+}
+
+func TestGenerate_HelperCollisions(t *testing.T) {
+	dir := fstest.MapFS{
+		"service.go": &fstest.MapFile{Data: []byte(`package svc
+
+type ProxyGen struct{}
+
+func (p *ProxyGen) Get() {}
+`)},
+		"proxy_gen.go": &fstest.MapFile{Data: []byte(`// Code generated by Mikaël's proxy generator. DO NOT EDIT.
+
+package svc
+
+type _ProxyGenMethod struct{}
+`)},
+		"ProxyGen_proxy_gen.go": &fstest.MapFile{Data: []byte(`// Code generated by Mikaël's proxy generator. DO NOT EDIT.
+
+package svc
+
+func _ProxyGenResult() {}
+`)},
+	}
+
+	// The helpers of ProxyGen_proxy_gen.go, which is generated again, clash with those of the combined proxy_gen.go.
+	_, err := Generate(context.Background(), Options{
+		Dir:         dir,
+		PackageName: "svc",
+		TypeNames:   []string{"ProxyGen"},
+	})
+
+	expected := Diagnostics{
+		{Message: "_ProxyGenMethod of the helpers of ProxyGen_proxy_gen.go conflicts with a declaration of package svc in proxy_gen.go"},
+	}
+	if diagnostics, ok := err.(Diagnostics); !ok || !reflect.DeepEqual(diagnostics, expected) {
+		t.Errorf("Expected diagnostics %v, got %v", expected, err)
+	}
+}
+
+func TestGenerate_Errors(t *testing.T) {
+	dir := fstest.MapFS{
+		"service.go": &fstest.MapFile{Data: []byte(`package svc
+
+type Service struct{}
+
+type Cache[T any] struct{}
+`)},
+	}
+
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	testCases := []struct {
+		name          string
+		ctx           context.Context
+		options       Options
+		expectedError string
+	}{
+		{
+			name:          "Missing directory",
+			ctx:           context.Background(),
+			options:       Options{PackageName: "svc", TypeNames: []string{"Service"}},
+			expectedError: "no directory to read the package from",
+		},
+		{
+			name:          "Missing type names",
+			ctx:           context.Background(),
+			options:       Options{Dir: dir, PackageName: "svc"},
+			expectedError: "no types to proxy",
+		},
+		{
+			name:          "Invalid delegate kind",
+			ctx:           context.Background(),
+			options:       Options{Dir: dir, PackageName: "svc", TypeNames: []string{"Service"}, DelegateKind: "reference"},
+			expectedError: `invalid delegate kind "reference", expected value, pointer or auto`,
+		},
+		{
+			name:          "Invalid mode",
+			ctx:           context.Background(),
+			options:       Options{Dir: dir, PackageName: "svc", TypeNames: []string{"Service"}, Mode: "reflection"},
+			expectedError: `invalid mode "reflection", expected handler or hooks`,
+		},
+		{
+			name:          "Hooks for generic type",
+			ctx:           context.Background(),
+			options:       Options{Dir: dir, PackageName: "svc", TypeNames: []string{"Cache"}, Mode: "hooks"},
+			expectedError: "cannot generate hooks for generic type Cache",
+		},
+		{
+			name:          "Middleware in hooks mode",
+			ctx:           context.Background(),
+			options:       Options{Dir: dir, PackageName: "svc", TypeNames: []string{"Service"}, Mode: "hooks", Middleware: true},
+			expectedError: "middleware isn't available in hooks mode",
+		},
+		{
+			name:          "Middleware for generic type",
+			ctx:           context.Background(),
+			options:       Options{Dir: dir, PackageName: "svc", TypeNames: []string{"Cache"}, Middleware: true},
+			expectedError: "cannot generate middleware for generic type Cache",
+		},
+		{
+			name:          "Output package without import path",
+			ctx:           context.Background(),
+			options:       Options{Dir: dir, PackageName: "svc", TypeNames: []string{"Service"}, OutputPackage: "example.com/proxies"},
+			expectedError: "the import path of package svc is needed to import it from example.com/proxies",
+		},
+		{
+			name:          "Missing package",
+			ctx:           context.Background(),
+			options:       Options{Dir: dir, PackageName: "other", TypeNames: []string{"Service"}},
+			expectedError: "could not find go files for package other",
+		},
+		{
+			name:          "Cancelled context",
+			ctx:           cancelled,
+			options:       Options{Dir: dir, PackageName: "svc", TypeNames: []string{"Service"}},
+			expectedError: context.Canceled.Error(),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := Generate(tc.ctx, tc.options)
+			if err == nil || err.Error() != tc.expectedError {
+				t.Errorf("Expected error '%s', got '%v'", tc.expectedError, err)
+			}
+		})
+	}
+}
+
+func TestOutputPackageName(t *testing.T) {
+	testCases := []struct {
+		name          string
+		options       Options
+		expected      string
+		expectedError string
+	}{
+		{
+			name:     "Last element",
+			options:  Options{OutputPackage: "example.com/app/proxies"},
+			expected: "proxies",
+		},
+		{
+			name:     "Major version suffix",
+			options:  Options{OutputPackage: "example.com/app/proxies/v2"},
+			expected: "proxies",
+		},
+		{
+			name:     "Hyphenated element",
+			options:  Options{OutputPackage: "example.com/app/my-proxies"},
+			expected: "my_proxies",
+		},
+		{
+			name:     "Given name",
+			options:  Options{OutputPackage: "example.com/app/my-proxies", OutputPackageName: "proxies"},
+			expected: "proxies",
+		},
+		{
+			name:          "Invalid element",
+			options:       Options{OutputPackage: "example.com/app/2fa"},
+			expectedError: `invalid package name "2fa" for example.com/app/2fa`,
+		},
+		{
+			name:          "Invalid given name",
+			options:       Options{OutputPackage: "example.com/app/proxies", OutputPackageName: "type"},
+			expectedError: `invalid package name "type" for example.com/app/proxies`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := outputPackageName(tc.options)
+			if tc.expectedError != "" {
+				if err == nil || err.Error() != tc.expectedError {
+					t.Errorf("Expected error '%s', got '%v'", tc.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if got != tc.expected {
+				t.Errorf("Expected package name %s, got %s", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestGenerate_Diagnostics(t *testing.T) {
+	testCases := []struct {
+		name     string
+		dir      fstest.MapFS
+		expected Diagnostics
+	}{
+		{
+			name: "Parse errors in several files",
+			dir: fstest.MapFS{
+				"a.go": &fstest.MapFile{Data: []byte("package svc\n\nfunc a() {\n")},
+				"b.go": &fstest.MapFile{Data: []byte("package svc\n\ntype Service struct{\n")},
+			},
+			expected: Diagnostics{
+				{File: "a.go", Line: 3, Column: 12, Message: "expected ';', found 'EOF'"},
+				{File: "a.go", Line: 3, Column: 12, Message: "expected '}', found 'EOF'"},
+				{File: "b.go", Line: 3, Column: 22, Message: "expected ';', found 'EOF'"},
+				{File: "b.go", Line: 3, Column: 22, Message: "expected '}', found 'EOF'"},
+			},
+		},
+		{
+			name: "Unresolved signatures in several methods",
+			dir: fstest.MapFS{
+				"service.go": &fstest.MapFile{Data: []byte(`package svc
+
+type Service struct{}
+
+func (s *Service) Get(id ID) string { return "" }
+
+func (s *Service) Valid() {}
+
+func (s *Service) Put(value Value) {}
+`)},
+			},
+			expected: Diagnostics{
+				{File: "service.go", Line: 5, Column: 26, Method: "Get", Message: "undefined: ID"},
+				{File: "service.go", Line: 9, Column: 29, Method: "Put", Message: "undefined: Value"},
+				{Message: "could not find type declaration with name Missing"},
+			},
+		},
+		{
+			name: "Missing type",
+			dir: fstest.MapFS{
+				"service.go": &fstest.MapFile{Data: []byte("package svc\n\ntype Service struct{}\n")},
+			},
+			expected: Diagnostics{
+				{Message: "could not find type declaration with name Missing"},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := Generate(context.Background(), Options{
+				Dir:         tc.dir,
+				PackageName: "svc",
+				TypeNames:   []string{"Service", "Missing"},
+			})
+
+			diagnostics, ok := err.(Diagnostics)
+			if !ok {
+				t.Fatalf("Expected diagnostics, got %v", err)
+			}
+			if !reflect.DeepEqual(diagnostics, tc.expected) {
+				t.Errorf("Expected diagnostics %v, got %v", tc.expected, diagnostics)
+			}
+		})
+	}
+}
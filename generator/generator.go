@@ -1,23 +1,27 @@
 package generator
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/LeMikaelF/proxy-generator/generator/internal/flags"
-	"github.com/LeMikaelF/proxy-generator/generator/internal/method"
-	"github.com/LeMikaelF/proxy-generator/generator/internal/source"
-	"github.com/LeMikaelF/proxy-generator/generator/internal/tmpl"
-	"go/ast"
 	"go/parser"
 	"go/token"
+	"io"
+	"io/fs"
 	"os"
+	"path"
 	"path/filepath"
+	"sort"
+	"strings"
 )
 
 type fileHandler interface {
 	getwd() (string, error)
 	readFile(filename string) ([]byte, error) // Add ReadFile
 	writeFile(filename string, data []byte, perm os.FileMode) error
-	glob(pattern string) ([]string, error)
+	dirFS(dir string) fs.FS
 }
 
 type OsFileHandler struct{}
@@ -34,16 +38,18 @@ func (fh *OsFileHandler) writeFile(filename string, data []byte, perm os.FileMod
 	return os.WriteFile(filename, data, perm)
 }
 
-func (fh *OsFileHandler) glob(pattern string) ([]string, error) {
-	return filepath.Glob(pattern)
+func (fh *OsFileHandler) dirFS(dir string) fs.FS {
+	return os.DirFS(dir)
 }
 
+// Generator generates proxies from the command line, for the package in the working directory.
 type Generator struct {
-	workingDir         string
-	pkg                string
-	typeName           string
-	passthroughMethods map[string]bool
-	fileHandler        fileHandler
+	workingDir        string
+	options           Options
+	diagnosticsFormat string
+	tagsFile          string
+	fileHandler       fileHandler
+	stdout            io.Writer
 }
 
 func New() (*Generator, error) {
@@ -64,74 +70,168 @@ func new(fh fileHandler, parsedFlags *flags.ParsedFlags) (*Generator, error) {
 	g := &Generator{
 		workingDir:  workingDir,
 		fileHandler: fh,
+		stdout:      os.Stdout,
 	}
 
-	g.pkg = parsedFlags.PackageName
-	g.typeName = parsedFlags.TypeName
-	g.passthroughMethods = parsedFlags.PassthroughMethods
+	g.options = Options{
+		PackageName:        parsedFlags.PackageName,
+		TypeNames:          parsedFlags.TypeNames,
+		PassthroughMethods: parsedFlags.PassthroughMethods,
+		IncludeUnexported:  parsedFlags.IncludeUnexported,
+		DelegateKind:       parsedFlags.DelegateKind,
+		Mode:               parsedFlags.Mode,
+		Middleware:         parsedFlags.Middleware,
+		Implements:         parsedFlags.Implements,
+		EmitInterface:      parsedFlags.EmitInterface,
+		Combine:            parsedFlags.Combine,
+		Output:             parsedFlags.Output,
+		OutputPackage:      parsedFlags.OutputPackage,
+	}
+	g.diagnosticsFormat = parsedFlags.DiagnosticsFormat
+	g.tagsFile = parsedFlags.TagsFile
 
 	return g, nil
 }
 
+// Run generates the proxies, and writes them relative to the working directory, or to standard output for -.
 func (g *Generator) Run() error {
-	files, err := g.fileHandler.glob(g.workingDir + "/*.go")
-	if err != nil {
-		return fmt.Errorf("error finding go files: %v", err)
-	}
+	options := g.options
+	options.Dir = g.fileHandler.dirFS(g.workingDir)
 
-	fset := token.NewFileSet()
-
-	var structDecl *ast.GenDecl
-	var methods []method.Method
-	var packageName string
-	imports := make(map[string]struct{})
+	// The import path of the package is only needed to import it from another package.
+	if options.OutputPackage != "" {
+		importPath, err := g.importPath()
+		if err != nil {
+			return err
+		}
+		options.ImportPath = importPath
+
+		// The proxy joins the package of the files already in its directory, if any.
+		if options.Output != "-" {
+			packageName, err := g.existingPackageName(filepath.Dir(options.Output))
+			if err != nil {
+				return err
+			}
+			options.OutputPackageName = packageName
+		}
+	}
 
-	for _, file := range files {
-		fileData, err := g.fileHandler.readFile(file) // Read the file contents from the file handler
+	if g.tagsFile != "" {
+		data, err := g.fileHandler.readFile(g.tagsFile)
 		if err != nil {
-			return fmt.Errorf("error reading file %s: %v", file, err)
+			return fmt.Errorf("error reading tags file %s: %v", g.tagsFile, err)
 		}
-		fileNode, err := parser.ParseFile(fset, file, fileData, parser.AllErrors)
+		if err := json.Unmarshal(data, &options.MethodTags); err != nil {
+			return fmt.Errorf("error parsing tags file %s: %v", g.tagsFile, err)
+		}
+	}
+
+	files, err := Generate(context.Background(), options)
+	if err != nil {
+		return err
+	}
 
-		sourceFile := source.NewFile(fileNode)
+	fileNames := make([]string, 0, len(files))
+	for fileName := range files {
+		fileNames = append(fileNames, fileName)
+	}
+	sort.Strings(fileNames)
 
-		if fileNode.Name.Name != g.pkg {
-			continue
+	for _, fileName := range fileNames {
+		if err := g.write(fileName, files[fileName]); err != nil {
+			return err
 		}
+	}
 
-		if structDecl == nil {
-			structDecl = sourceFile.FindStructDeclaration(g.typeName)
-			packageName = fileNode.Name.Name
+	return nil
+}
+
+// Report writes the error returned by Run to w, in the requested diagnostics format. In the json format, it's written
+// as an array of diagnostics, even if it doesn't come from the source of the proxied types.
+func (g *Generator) Report(w io.Writer, err error) {
+	if g.diagnosticsFormat != "json" {
+		fmt.Fprintln(w, err)
+		return
+	}
+
+	var diagnostics Diagnostics
+	if !errors.As(err, &diagnostics) {
+		diagnostics = Diagnostics{{Message: err.Error()}}
+	}
+	if err := json.NewEncoder(w).Encode(diagnostics); err != nil {
+		fmt.Fprintln(w, err)
+	}
+}
+
+// importPath finds the import path of the package from the module containing the working directory.
+func (g *Generator) importPath() (string, error) {
+	for dir := g.workingDir; ; dir = filepath.Dir(dir) {
+		if data, err := g.fileHandler.readFile(filepath.Join(dir, "go.mod")); err == nil {
+			modulePath := modulePath(data)
+			if modulePath == "" {
+				return "", fmt.Errorf("could not find module path in %s", filepath.Join(dir, "go.mod"))
+			}
+			rel, err := filepath.Rel(dir, g.workingDir)
+			if err != nil {
+				return "", fmt.Errorf("error finding import path of package %s: %v", g.options.PackageName, err)
+			}
+			return path.Join(modulePath, filepath.ToSlash(rel)), nil
 		}
 
-		newMethods, newImports := sourceFile.FindMethods(g.typeName, g.passthroughMethods)
-		methods = append(methods, newMethods...)
-		for _, newImport := range newImports {
-			imports[newImport] = struct{}{}
+		if filepath.Dir(dir) == dir {
+			return "", fmt.Errorf("could not find go.mod for package %s, which is needed to import it from %s", g.options.PackageName, g.options.OutputPackage)
 		}
 	}
+}
 
-	if structDecl == nil {
-		return fmt.Errorf("could not find struct declaration with name %s", g.typeName)
+// existingPackageName returns the package declared by the Go files in dir, relative to the working directory, or an
+// empty string if it has none. Test files and files which don't parse are ignored.
+func (g *Generator) existingPackageName(dir string) (string, error) {
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(g.workingDir, dir)
 	}
 
-	template := tmpl.New(packageName, g.typeName, methods, toSlice(imports))
-	generatedCode, err := template.Render()
+	fsys := g.fileHandler.dirFS(dir)
+	files, err := fs.Glob(fsys, "*.go")
 	if err != nil {
-		return err
+		return "", fmt.Errorf("error finding go files in %s: %v", dir, err)
 	}
-
-	generatedFileName := fmt.Sprintf("%s_proxy_gen.go", g.typeName)
-	if err := g.fileHandler.writeFile(generatedFileName, []byte(generatedCode), 0666); err != nil {
-		return fmt.Errorf("error outputting code: %v", err)
+	for _, file := range files {
+		if strings.HasSuffix(file, "_test.go") {
+			continue
+		}
+		data, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return "", fmt.Errorf("error reading file %s: %v", file, err)
+		}
+		if fileNode, err := parser.ParseFile(token.NewFileSet(), file, data, parser.PackageClauseOnly); err == nil {
+			return fileNode.Name.Name, nil
+		}
 	}
+	return "", nil
+}
 
-	return nil
+// modulePath returns the path declared by the module directive of a go.mod file.
+func modulePath(goMod []byte) string {
+	for _, line := range strings.Split(string(goMod), "\n") {
+		if fields := strings.Fields(line); len(fields) >= 2 && fields[0] == "module" {
+			return strings.Trim(fields[1], `"`)
+		}
+	}
+	return ""
 }
 
-func toSlice(m map[string]struct{}) (slice []string) {
-	for k := range m {
-		slice = append(slice, k)
+func (g *Generator) write(fileName string, generatedCode []byte) error {
+	if fileName == "-" {
+		if _, err := g.stdout.Write(generatedCode); err != nil {
+			return fmt.Errorf("error outputting code: %v", err)
+		}
+		return nil
 	}
-	return slice
+
+	if err := g.fileHandler.writeFile(fileName, generatedCode, 0666); err != nil {
+		return fmt.Errorf("error outputting code: %v", err)
+	}
+
+	return nil
 }
@@ -1,18 +1,27 @@
 package generator
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"github.com/LeMikaelF/proxy-generator/generator/internal/flags"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io/fs"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"testing/fstest"
 )
 
 type mockFileHandler struct {
 	GetwdFunc     func() (string, error)
 	ReadFileFunc  func(filename string) ([]byte, error) // Add ReadFileFunc
 	WriteFileFunc func(filename string, data []byte, perm os.FileMode) error
-	GlobFunc      func(pattern string) ([]string, error)
 	data          map[string][]byte
 }
 
@@ -24,16 +33,17 @@ func (m *mockFileHandler) readFile(filename string) ([]byte, error) {
 	return data, nil
 }
 
-func (m *mockFileHandler) glob(pattern string) ([]string, error) {
-	if m.GlobFunc != nil {
-		return m.GlobFunc(pattern)
-	}
-	filenames := make([]string, 0, len(m.data))
-	for filename := range m.data {
-		filenames = append(filenames, filename)
+// dirFS returns the files of dir, named relative to it.
+func (m *mockFileHandler) dirFS(dir string) fs.FS {
+	fsys := fstest.MapFS{}
+	for filename, data := range m.data {
+		if filepath.Dir(filename) == dir {
+			fsys[filepath.Base(filename)] = &fstest.MapFile{Data: data}
+		}
 	}
-	return filenames, nil
+	return fsys
 }
+
 func (m *mockFileHandler) getwd() (string, error) {
 	if m.GetwdFunc != nil {
 		return m.GetwdFunc()
@@ -86,7 +96,7 @@ func TestGenerator_New(t *testing.T) {
 func newMockFlags() *flags.ParsedFlags {
 	return &flags.ParsedFlags{
 		PackageName:        "mypackage",
-		TypeName:           "MyType",
+		TypeNames:          []string{"MyType"},
 		PassthroughMethods: map[string]bool{},
 	}
 }
@@ -109,13 +119,17 @@ func (m *MyType) Foo() {}
 `,
 			flags: &flags.ParsedFlags{
 				PackageName:        "test",
-				TypeName:           "MyType",
+				TypeNames:          []string{"MyType"},
 				PassthroughMethods: map[string]bool{},
 			},
 			expectedOutput: `package test
 
 // Code generated by Mikaël's proxy generator. DO NOT EDIT.
 
+import (
+	fmt "fmt"
+)
+
 type MyTypeProxy struct {
 	delegate          *MyType
 	invocationHandler func(method interface {
@@ -124,12 +138,81 @@ type MyTypeProxy struct {
 		Name() string
 		Invoke(args []any) []any
 	}, args []any) []any
+	invocations []_MyTypeProxyInvocation
+}
+
+func (d *MyTypeProxy) Foo() {
+
+	var args []any
+	d.invocationHandler(&d.invocations[0], args)
+
+}
+
+// _MyTypeProxyMethods describes the methods of MyTypeProxy, in the order of its invocations.
+var _MyTypeProxyMethods = [...]_MyTypeMethod{
+	{
+		methodName:   "Foo",
+		receiver:     "*MyType",
+		errorIndex:   -1,
+		contextIndex: -1,
+		position:     "testfile.go:5",
+	},
+}
+
+// _MyTypeProxyInvocation binds the descriptor of a method to the delegate of a proxy, so that proxied calls don't
+// allocate it.
+type _MyTypeProxyInvocation struct {
+	*_MyTypeMethod
+	delegate *MyType
+	invoke   func(*_MyTypeProxyInvocation, []any) []any
+}
+
+func (i *_MyTypeProxyInvocation) Invoke(args []any) []any { return i.invoke(i, args) }
+
+func (d *_MyTypeProxyInvocation) invokeFoo(args []any) []any {
+	d.delegate.Foo()
+	return []any{}
+}
+
+func NewMyTypeProxy(delegate *MyType, invocationHandler func(method interface {
+	Package() string
+	Receiver() string
+	Name() string
+	Invoke(args []any) []any
+}, args []any) (retVals []any)) *MyTypeProxy {
+	if invocationHandler == nil {
+		invocationHandler = func(method interface {
+			Package() string
+			Receiver() string
+			Name() string
+			Invoke(args []any) []any
+		}, args []any) []any {
+			return method.Invoke(args)
+		}
+	}
+
+	return &MyTypeProxy{
+		delegate:          delegate,
+		invocationHandler: invocationHandler,
+		invocations: []_MyTypeProxyInvocation{
+			{&_MyTypeProxyMethods[0], delegate, (*_MyTypeProxyInvocation).invokeFoo},
+		},
+	}
 }
 
+// _MyTypeMethod describes a proxied method. Descriptors are package-level values, shared by every proxy, so they
+// return copies of their slices and maps, which handlers may modify.
 type _MyTypeMethod struct {
-	methodName string
-	receiver   string
-	method     func([]any) []any
+	methodName   string
+	receiver     string
+	tags         map[string]string
+	paramNames   []string
+	paramTypes   []string
+	resultTypes  []string
+	variadic     bool
+	errorIndex   int
+	contextIndex int
+	position     string
 }
 
 func (m *_MyTypeMethod) Name() string { return m.methodName }
@@ -138,22 +221,324 @@ func (m *_MyTypeMethod) Receiver() string { return m.receiver }
 
 func (m *_MyTypeMethod) Package() string { return "test" }
 
-func (m *_MyTypeMethod) Invoke(args []any) []any { return m.method(args) }
+// Tags returns the tags of the method, set by //proxy:tag directives or a tags file. Invocation handlers can read them
+// with a type assertion to interface { Tags() map[string]string }.
+func (m *_MyTypeMethod) Tags() map[string]string {
+	if m.tags == nil {
+		return nil
+	}
+	tags := make(map[string]string, len(m.tags))
+	for key, value := range m.tags {
+		tags[key] = value
+	}
+	return tags
+}
+
+// ParamNames returns the names of the method's parameters, in the order of args. Unnamed parameters are named after their
+// position, as in p0.
+func (m *_MyTypeMethod) ParamNames() []string { return append([]string(nil), m.paramNames...) }
 
-func (d *MyTypeProxy) Foo() {
+// ParamTypes returns the declared types of the method's parameters, such as ...string for a variadic parameter, which
+// is passed in args as a slice.
+func (m *_MyTypeMethod) ParamTypes() []string { return append([]string(nil), m.paramTypes...) }
+
+// ResultTypes returns the declared types of the method's results.
+func (m *_MyTypeMethod) ResultTypes() []string { return append([]string(nil), m.resultTypes...) }
+
+// Variadic reports whether the method's last parameter is variadic.
+func (m *_MyTypeMethod) Variadic() bool { return m.variadic }
+
+// ErrorIndex returns the index of the method's trailing error result, or -1 if it doesn't return an error.
+func (m *_MyTypeMethod) ErrorIndex() int { return m.errorIndex }
+
+// ContextIndex returns the index of the method's leading context.Context parameter, or -1 if it doesn't take one.
+func (m *_MyTypeMethod) ContextIndex() int { return m.contextIndex }
+
+// Position returns the file:line of the method's declaration, or an empty string if it's unknown.
+func (m *_MyTypeMethod) Position() string { return m.position }
+
+func _MyTypeCheckResults(results []any, count int, methodName string) {
+	if len(results) != count {
+		panic(fmt.Sprintf("invocation handler returned %d results for %s, expected %d", len(results), methodName, count))
+	}
+}
+
+func _MyTypeResult[T any](results []any, index int, methodName string, typeName string) T {
+	// A nil result, such as a nil error, converts to the zero value of its declared type.
+	result, ok := results[index].(T)
+	if !ok && results[index] != nil {
+		panic(fmt.Sprintf("invocation handler returned %T at index %d for %s, expected %s", results[index], index, methodName, typeName))
+	}
+	return result
+}
+`,
+			expectedError: nil,
+		},
+		{
+			name: "Variadic method",
+			input: `package test
 
-	method := _MyTypeMethod{
-		methodName: "Foo",
-		receiver:   "*MyType",
-		method: func(args []any) []any {
-			d.delegate.Foo()
-			return []any{}
+type MyType struct {}
+
+func (m *MyType) Logf(format string, values ...any) {}
+`,
+			flags: &flags.ParsedFlags{
+				PackageName:        "test",
+				TypeNames:          []string{"MyType"},
+				PassthroughMethods: map[string]bool{},
+			},
+			expectedOutput: `package test
+
+// Code generated by Mikaël's proxy generator. DO NOT EDIT.
+
+import (
+	fmt "fmt"
+)
+
+type MyTypeProxy struct {
+	delegate          *MyType
+	invocationHandler func(method interface {
+		Package() string
+		Receiver() string
+		Name() string
+		Invoke(args []any) []any
+	}, args []any) []any
+	invocations []_MyTypeProxyInvocation
+}
+
+func (d *MyTypeProxy) Logf(format string, values ...any) {
+
+	var args []any = []any{format, values}
+	d.invocationHandler(&d.invocations[0], args)
+
+}
+
+// _MyTypeProxyMethods describes the methods of MyTypeProxy, in the order of its invocations.
+var _MyTypeProxyMethods = [...]_MyTypeMethod{
+	{
+		methodName:   "Logf",
+		receiver:     "*MyType",
+		paramNames:   []string{"format", "values"},
+		paramTypes:   []string{"string", "...any"},
+		variadic:     true,
+		errorIndex:   -1,
+		contextIndex: -1,
+		position:     "testfile.go:5",
+	},
+}
+
+// _MyTypeProxyInvocation binds the descriptor of a method to the delegate of a proxy, so that proxied calls don't
+// allocate it.
+type _MyTypeProxyInvocation struct {
+	*_MyTypeMethod
+	delegate *MyType
+	invoke   func(*_MyTypeProxyInvocation, []any) []any
+}
+
+func (i *_MyTypeProxyInvocation) Invoke(args []any) []any { return i.invoke(i, args) }
+
+func (d *_MyTypeProxyInvocation) invokeLogf(args []any) []any {
+	d.delegate.Logf(args[0].(string), args[1].([]any)...)
+	return []any{}
+}
+
+func NewMyTypeProxy(delegate *MyType, invocationHandler func(method interface {
+	Package() string
+	Receiver() string
+	Name() string
+	Invoke(args []any) []any
+}, args []any) (retVals []any)) *MyTypeProxy {
+	if invocationHandler == nil {
+		invocationHandler = func(method interface {
+			Package() string
+			Receiver() string
+			Name() string
+			Invoke(args []any) []any
+		}, args []any) []any {
+			return method.Invoke(args)
+		}
+	}
+
+	return &MyTypeProxy{
+		delegate:          delegate,
+		invocationHandler: invocationHandler,
+		invocations: []_MyTypeProxyInvocation{
+			{&_MyTypeProxyMethods[0], delegate, (*_MyTypeProxyInvocation).invokeLogf},
 		},
 	}
+}
+
+// _MyTypeMethod describes a proxied method. Descriptors are package-level values, shared by every proxy, so they
+// return copies of their slices and maps, which handlers may modify.
+type _MyTypeMethod struct {
+	methodName   string
+	receiver     string
+	tags         map[string]string
+	paramNames   []string
+	paramTypes   []string
+	resultTypes  []string
+	variadic     bool
+	errorIndex   int
+	contextIndex int
+	position     string
+}
+
+func (m *_MyTypeMethod) Name() string { return m.methodName }
+
+func (m *_MyTypeMethod) Receiver() string { return m.receiver }
+
+func (m *_MyTypeMethod) Package() string { return "test" }
+
+// Tags returns the tags of the method, set by //proxy:tag directives or a tags file. Invocation handlers can read them
+// with a type assertion to interface { Tags() map[string]string }.
+func (m *_MyTypeMethod) Tags() map[string]string {
+	if m.tags == nil {
+		return nil
+	}
+	tags := make(map[string]string, len(m.tags))
+	for key, value := range m.tags {
+		tags[key] = value
+	}
+	return tags
+}
+
+// ParamNames returns the names of the method's parameters, in the order of args. Unnamed parameters are named after their
+// position, as in p0.
+func (m *_MyTypeMethod) ParamNames() []string { return append([]string(nil), m.paramNames...) }
+
+// ParamTypes returns the declared types of the method's parameters, such as ...string for a variadic parameter, which
+// is passed in args as a slice.
+func (m *_MyTypeMethod) ParamTypes() []string { return append([]string(nil), m.paramTypes...) }
+
+// ResultTypes returns the declared types of the method's results.
+func (m *_MyTypeMethod) ResultTypes() []string { return append([]string(nil), m.resultTypes...) }
+
+// Variadic reports whether the method's last parameter is variadic.
+func (m *_MyTypeMethod) Variadic() bool { return m.variadic }
+
+// ErrorIndex returns the index of the method's trailing error result, or -1 if it doesn't return an error.
+func (m *_MyTypeMethod) ErrorIndex() int { return m.errorIndex }
+
+// ContextIndex returns the index of the method's leading context.Context parameter, or -1 if it doesn't take one.
+func (m *_MyTypeMethod) ContextIndex() int { return m.contextIndex }
+
+// Position returns the file:line of the method's declaration, or an empty string if it's unknown.
+func (m *_MyTypeMethod) Position() string { return m.position }
+
+func _MyTypeCheckResults(results []any, count int, methodName string) {
+	if len(results) != count {
+		panic(fmt.Sprintf("invocation handler returned %d results for %s, expected %d", len(results), methodName, count))
+	}
+}
+
+func _MyTypeResult[T any](results []any, index int, methodName string, typeName string) T {
+	// A nil result, such as a nil error, converts to the zero value of its declared type.
+	result, ok := results[index].(T)
+	if !ok && results[index] != nil {
+		panic(fmt.Sprintf("invocation handler returned %T at index %d for %s, expected %s", results[index], index, methodName, typeName))
+	}
+	return result
+}
+`,
+			expectedError: nil,
+		},
+		{
+			name: "Function-typed parameters and results",
+			input: `package test
+
+import "context"
+
+type Item struct{}
+
+type MyType struct{}
+
+func (m *MyType) Each(ctx context.Context, fn func(ctx context.Context, item Item) error) error { return nil }
+
+func (m *MyType) Visitor() func(Item, ...string) (bool, error) { return nil }
+`,
+			flags: &flags.ParsedFlags{
+				PackageName:        "test",
+				TypeNames:          []string{"MyType"},
+				PassthroughMethods: map[string]bool{},
+			},
+			expectedOutput: `package test
+
+// Code generated by Mikaël's proxy generator. DO NOT EDIT.
+
+import (
+	context "context"
+	fmt "fmt"
+)
+
+type MyTypeProxy struct {
+	delegate          *MyType
+	invocationHandler func(method interface {
+		Package() string
+		Receiver() string
+		Name() string
+		Invoke(args []any) []any
+	}, args []any) []any
+	invocations []_MyTypeProxyInvocation
+}
+
+func (d *MyTypeProxy) Each(ctx context.Context, fn func(ctx context.Context, item Item) error) error {
+
+	var args []any = []any{ctx, fn}
+	results := d.invocationHandler(&d.invocations[0], args)
+	_MyTypeCheckResults(results, 1, "MyType.Each")
+	return _MyTypeResult[error](results, 0, "MyType.Each", "error")
+
+}
+
+func (d *MyTypeProxy) Visitor() func(Item, ...string) (bool, error) {
 
 	var args []any
-	d.invocationHandler(&method, args)
+	results := d.invocationHandler(&d.invocations[1], args)
+	_MyTypeCheckResults(results, 1, "MyType.Visitor")
+	return _MyTypeResult[func(Item, ...string) (bool, error)](results, 0, "MyType.Visitor", "func(Item, ...string) (bool, error)")
+
+}
 
+// _MyTypeProxyMethods describes the methods of MyTypeProxy, in the order of its invocations.
+var _MyTypeProxyMethods = [...]_MyTypeMethod{
+	{
+		methodName:   "Each",
+		receiver:     "*MyType",
+		paramNames:   []string{"ctx", "fn"},
+		paramTypes:   []string{"context.Context", "func(ctx context.Context, item Item) error"},
+		resultTypes:  []string{"error"},
+		errorIndex:   0,
+		contextIndex: 0,
+		position:     "testfile.go:9",
+	},
+	{
+		methodName:   "Visitor",
+		receiver:     "*MyType",
+		resultTypes:  []string{"func(Item, ...string) (bool, error)"},
+		errorIndex:   -1,
+		contextIndex: -1,
+		position:     "testfile.go:11",
+	},
+}
+
+// _MyTypeProxyInvocation binds the descriptor of a method to the delegate of a proxy, so that proxied calls don't
+// allocate it.
+type _MyTypeProxyInvocation struct {
+	*_MyTypeMethod
+	delegate *MyType
+	invoke   func(*_MyTypeProxyInvocation, []any) []any
+}
+
+func (i *_MyTypeProxyInvocation) Invoke(args []any) []any { return i.invoke(i, args) }
+
+func (d *_MyTypeProxyInvocation) invokeEach(args []any) []any {
+	result0 := d.delegate.Each(args[0].(context.Context), args[1].(func(ctx context.Context, item Item) error))
+	return []any{result0}
+}
+
+func (d *_MyTypeProxyInvocation) invokeVisitor(args []any) []any {
+	result0 := d.delegate.Visitor()
+	return []any{result0}
 }
 
 func NewMyTypeProxy(delegate *MyType, invocationHandler func(method interface {
@@ -176,45 +561,4007 @@ func NewMyTypeProxy(delegate *MyType, invocationHandler func(method interface {
 	return &MyTypeProxy{
 		delegate:          delegate,
 		invocationHandler: invocationHandler,
+		invocations: []_MyTypeProxyInvocation{
+			{&_MyTypeProxyMethods[0], delegate, (*_MyTypeProxyInvocation).invokeEach},
+			{&_MyTypeProxyMethods[1], delegate, (*_MyTypeProxyInvocation).invokeVisitor},
+		},
 	}
 }
+
+// _MyTypeMethod describes a proxied method. Descriptors are package-level values, shared by every proxy, so they
+// return copies of their slices and maps, which handlers may modify.
+type _MyTypeMethod struct {
+	methodName   string
+	receiver     string
+	tags         map[string]string
+	paramNames   []string
+	paramTypes   []string
+	resultTypes  []string
+	variadic     bool
+	errorIndex   int
+	contextIndex int
+	position     string
+}
+
+func (m *_MyTypeMethod) Name() string { return m.methodName }
+
+func (m *_MyTypeMethod) Receiver() string { return m.receiver }
+
+func (m *_MyTypeMethod) Package() string { return "test" }
+
+// Tags returns the tags of the method, set by //proxy:tag directives or a tags file. Invocation handlers can read them
+// with a type assertion to interface { Tags() map[string]string }.
+func (m *_MyTypeMethod) Tags() map[string]string {
+	if m.tags == nil {
+		return nil
+	}
+	tags := make(map[string]string, len(m.tags))
+	for key, value := range m.tags {
+		tags[key] = value
+	}
+	return tags
+}
+
+// ParamNames returns the names of the method's parameters, in the order of args. Unnamed parameters are named after their
+// position, as in p0.
+func (m *_MyTypeMethod) ParamNames() []string { return append([]string(nil), m.paramNames...) }
+
+// ParamTypes returns the declared types of the method's parameters, such as ...string for a variadic parameter, which
+// is passed in args as a slice.
+func (m *_MyTypeMethod) ParamTypes() []string { return append([]string(nil), m.paramTypes...) }
+
+// ResultTypes returns the declared types of the method's results.
+func (m *_MyTypeMethod) ResultTypes() []string { return append([]string(nil), m.resultTypes...) }
+
+// Variadic reports whether the method's last parameter is variadic.
+func (m *_MyTypeMethod) Variadic() bool { return m.variadic }
+
+// ErrorIndex returns the index of the method's trailing error result, or -1 if it doesn't return an error.
+func (m *_MyTypeMethod) ErrorIndex() int { return m.errorIndex }
+
+// ContextIndex returns the index of the method's leading context.Context parameter, or -1 if it doesn't take one.
+func (m *_MyTypeMethod) ContextIndex() int { return m.contextIndex }
+
+// Position returns the file:line of the method's declaration, or an empty string if it's unknown.
+func (m *_MyTypeMethod) Position() string { return m.position }
+
+func _MyTypeCheckResults(results []any, count int, methodName string) {
+	if len(results) != count {
+		panic(fmt.Sprintf("invocation handler returned %d results for %s, expected %d", len(results), methodName, count))
+	}
+}
+
+func _MyTypeResult[T any](results []any, index int, methodName string, typeName string) T {
+	// A nil result, such as a nil error, converts to the zero value of its declared type.
+	result, ok := results[index].(T)
+	if !ok && results[index] != nil {
+		panic(fmt.Sprintf("invocation handler returned %T at index %d for %s, expected %s", results[index], index, methodName, typeName))
+	}
+	return result
+}
 `,
 			expectedError: nil,
 		},
-	}
+		{
+			name: "Generic receiver type",
+			input: `package test
 
-	for _, tc := range testCases {
-		t.Run(tc.name, func(t *testing.T) {
-			mockFH := &mockFileHandler{data: make(map[string][]byte)}
+import "fmt"
 
-			// Write the input file.
-			mockFH.data["testfile.go"] = []byte(tc.input)
+type Repo[T fmt.Stringer, K comparable] struct{}
 
-			g, err := new(mockFH, newMockFlags())
-			if err != nil {
-				t.Fatalf("Unexpected error: %v", err)
-			}
+func (r *Repo[T, K]) Get(key K) (T, error) {
+	var t T
+	return t, nil
+}
 
-			// Set the package name, type name, and passthrough methods from the test case.
-			g.pkg = tc.flags.PackageName
-			g.typeName = tc.flags.TypeName
-			g.passthroughMethods = tc.flags.PassthroughMethods
+func (r *Repo[V, _]) All() map[string][]V { return nil }
 
-			err = g.Run()
-			if tc.expectedError == nil && err != nil {
-				t.Fatalf("Expected no error, got %v", err)
-			} else if tc.expectedError != nil && err == nil {
-				t.Fatal("Expected an error, got nil")
-			} else if tc.expectedError != nil && err != nil && err.Error() != tc.expectedError.Error() {
-				t.Fatalf("Expected error message '%s', got '%s'", tc.expectedError.Error(), err.Error())
-			}
+type Page[T any] struct{}
 
-			if err == nil {
-				generatedFileName := fmt.Sprintf("%s_proxy_gen.go", g.typeName)
-				output := string(mockFH.data[generatedFileName])
-				if output != tc.expectedOutput {
-					t.Errorf("Generated code does not match the expected output.\nExpected:\n%s\nGot:\n%s", tc.expectedOutput, output)
-				}
+func (r *Repo[T, K]) List(page Page[T], keys map[K]Page[T]) []T { return nil }
+`,
+			flags: &flags.ParsedFlags{
+				PackageName:        "test",
+				TypeNames:          []string{"Repo"},
+				PassthroughMethods: map[string]bool{},
+			},
+			expectedOutput: `package test
+
+// Code generated by Mikaël's proxy generator. DO NOT EDIT.
+
+import (
+	fmt "fmt"
+)
+
+type RepoProxy[T fmt.Stringer, K comparable] struct {
+	delegate          *Repo[T, K]
+	invocationHandler func(method interface {
+		Package() string
+		Receiver() string
+		Name() string
+		Invoke(args []any) []any
+	}, args []any) []any
+	invocations []_RepoProxyInvocation[T, K]
+}
+
+func (d *RepoProxy[T, K]) Get(key K) (T, error) {
+
+	var args []any = []any{key}
+	results := d.invocationHandler(&d.invocations[0], args)
+	_RepoCheckResults(results, 2, "Repo.Get")
+	return _RepoResult[T](results, 0, "Repo.Get", "T"), _RepoResult[error](results, 1, "Repo.Get", "error")
+
+}
+
+func (d *RepoProxy[V, _]) All() map[string][]V {
+
+	var args []any
+	results := d.invocationHandler(&d.invocations[1], args)
+	_RepoCheckResults(results, 1, "Repo.All")
+	return _RepoResult[map[string][]V](results, 0, "Repo.All", "map[string][]V")
+
+}
+
+func (d *RepoProxy[T, K]) List(page Page[T], keys map[K]Page[T]) []T {
+
+	var args []any = []any{page, keys}
+	results := d.invocationHandler(&d.invocations[2], args)
+	_RepoCheckResults(results, 1, "Repo.List")
+	return _RepoResult[[]T](results, 0, "Repo.List", "[]T")
+
+}
+
+// _RepoProxyMethods describes the methods of RepoProxy, in the order of its invocations.
+var _RepoProxyMethods = [...]_RepoMethod{
+	{
+		methodName:   "Get",
+		receiver:     "*Repo[T, K]",
+		paramNames:   []string{"key"},
+		paramTypes:   []string{"K"},
+		resultTypes:  []string{"T", "error"},
+		errorIndex:   1,
+		contextIndex: -1,
+		position:     "testfile.go:7",
+	},
+	{
+		methodName:   "All",
+		receiver:     "*Repo[V, _]",
+		resultTypes:  []string{"map[string][]V"},
+		errorIndex:   -1,
+		contextIndex: -1,
+		position:     "testfile.go:12",
+	},
+	{
+		methodName:   "List",
+		receiver:     "*Repo[T, K]",
+		paramNames:   []string{"page", "keys"},
+		paramTypes:   []string{"Page[T]", "map[K]Page[T]"},
+		resultTypes:  []string{"[]T"},
+		errorIndex:   -1,
+		contextIndex: -1,
+		position:     "testfile.go:16",
+	},
+}
+
+// _RepoProxyInvocation binds the descriptor of a method to the delegate of a proxy, so that proxied calls don't
+// allocate it.
+type _RepoProxyInvocation[T fmt.Stringer, K comparable] struct {
+	*_RepoMethod
+	delegate *Repo[T, K]
+	invoke   func(*_RepoProxyInvocation[T, K], []any) []any
+}
+
+func (i *_RepoProxyInvocation[T, K]) Invoke(args []any) []any { return i.invoke(i, args) }
+
+func (d *_RepoProxyInvocation[T, K]) invokeGet(args []any) []any {
+	result0, result1 := d.delegate.Get(args[0].(K))
+	return []any{result0, result1}
+}
+
+func (d *_RepoProxyInvocation[V, _]) invokeAll(args []any) []any {
+	result0 := d.delegate.All()
+	return []any{result0}
+}
+
+func (d *_RepoProxyInvocation[T, K]) invokeList(args []any) []any {
+	result0 := d.delegate.List(args[0].(Page[T]), args[1].(map[K]Page[T]))
+	return []any{result0}
+}
+
+func NewRepoProxy[T fmt.Stringer, K comparable](delegate *Repo[T, K], invocationHandler func(method interface {
+	Package() string
+	Receiver() string
+	Name() string
+	Invoke(args []any) []any
+}, args []any) (retVals []any)) *RepoProxy[T, K] {
+	if invocationHandler == nil {
+		invocationHandler = func(method interface {
+			Package() string
+			Receiver() string
+			Name() string
+			Invoke(args []any) []any
+		}, args []any) []any {
+			return method.Invoke(args)
+		}
+	}
+
+	return &RepoProxy[T, K]{
+		delegate:          delegate,
+		invocationHandler: invocationHandler,
+		invocations: []_RepoProxyInvocation[T, K]{
+			{&_RepoProxyMethods[0], delegate, (*_RepoProxyInvocation[T, K]).invokeGet},
+			{&_RepoProxyMethods[1], delegate, (*_RepoProxyInvocation[T, K]).invokeAll},
+			{&_RepoProxyMethods[2], delegate, (*_RepoProxyInvocation[T, K]).invokeList},
+		},
+	}
+}
+
+// _RepoMethod describes a proxied method. Descriptors are package-level values, shared by every proxy, so they
+// return copies of their slices and maps, which handlers may modify.
+type _RepoMethod struct {
+	methodName   string
+	receiver     string
+	tags         map[string]string
+	paramNames   []string
+	paramTypes   []string
+	resultTypes  []string
+	variadic     bool
+	errorIndex   int
+	contextIndex int
+	position     string
+}
+
+func (m *_RepoMethod) Name() string { return m.methodName }
+
+func (m *_RepoMethod) Receiver() string { return m.receiver }
+
+func (m *_RepoMethod) Package() string { return "test" }
+
+// Tags returns the tags of the method, set by //proxy:tag directives or a tags file. Invocation handlers can read them
+// with a type assertion to interface { Tags() map[string]string }.
+func (m *_RepoMethod) Tags() map[string]string {
+	if m.tags == nil {
+		return nil
+	}
+	tags := make(map[string]string, len(m.tags))
+	for key, value := range m.tags {
+		tags[key] = value
+	}
+	return tags
+}
+
+// ParamNames returns the names of the method's parameters, in the order of args. Unnamed parameters are named after their
+// position, as in p0.
+func (m *_RepoMethod) ParamNames() []string { return append([]string(nil), m.paramNames...) }
+
+// ParamTypes returns the declared types of the method's parameters, such as ...string for a variadic parameter, which
+// is passed in args as a slice.
+func (m *_RepoMethod) ParamTypes() []string { return append([]string(nil), m.paramTypes...) }
+
+// ResultTypes returns the declared types of the method's results.
+func (m *_RepoMethod) ResultTypes() []string { return append([]string(nil), m.resultTypes...) }
+
+// Variadic reports whether the method's last parameter is variadic.
+func (m *_RepoMethod) Variadic() bool { return m.variadic }
+
+// ErrorIndex returns the index of the method's trailing error result, or -1 if it doesn't return an error.
+func (m *_RepoMethod) ErrorIndex() int { return m.errorIndex }
+
+// ContextIndex returns the index of the method's leading context.Context parameter, or -1 if it doesn't take one.
+func (m *_RepoMethod) ContextIndex() int { return m.contextIndex }
+
+// Position returns the file:line of the method's declaration, or an empty string if it's unknown.
+func (m *_RepoMethod) Position() string { return m.position }
+
+func _RepoCheckResults(results []any, count int, methodName string) {
+	if len(results) != count {
+		panic(fmt.Sprintf("invocation handler returned %d results for %s, expected %d", len(results), methodName, count))
+	}
+}
+
+func _RepoResult[T any](results []any, index int, methodName string, typeName string) T {
+	// A nil result, such as a nil error, converts to the zero value of its declared type.
+	result, ok := results[index].(T)
+	if !ok && results[index] != nil {
+		panic(fmt.Sprintf("invocation handler returned %T at index %d for %s, expected %s", results[index], index, methodName, typeName))
+	}
+	return result
+}
+`,
+			expectedError: nil,
+		},
+		{
+			name: "Interface with embedded interfaces",
+			input: `package test
+
+import (
+	"context"
+	"io"
+)
+
+type Closer interface {
+	Close() error
+}
+
+type Store interface {
+	io.Reader
+	Closer
+	Get(ctx context.Context, key string) ([]byte, error)
+	Put(ctx context.Context, key string, value []byte) error
+}
+`,
+			flags: &flags.ParsedFlags{
+				PackageName:        "test",
+				TypeNames:          []string{"Store"},
+				PassthroughMethods: map[string]bool{},
+			},
+			expectedOutput: `package test
+
+// Code generated by Mikaël's proxy generator. DO NOT EDIT.
+
+import (
+	context "context"
+	fmt "fmt"
+)
+
+type StoreProxy struct {
+	delegate          Store
+	invocationHandler func(method interface {
+		Package() string
+		Receiver() string
+		Name() string
+		Invoke(args []any) []any
+	}, args []any) []any
+	invocations []_StoreProxyInvocation
+}
+
+func (d *StoreProxy) Close() error {
+
+	var args []any
+	results := d.invocationHandler(&d.invocations[0], args)
+	_StoreCheckResults(results, 1, "Store.Close")
+	return _StoreResult[error](results, 0, "Store.Close", "error")
+
+}
+
+func (d *StoreProxy) Get(ctx context.Context, key string) ([]byte, error) {
+
+	var args []any = []any{ctx, key}
+	results := d.invocationHandler(&d.invocations[1], args)
+	_StoreCheckResults(results, 2, "Store.Get")
+	return _StoreResult[[]byte](results, 0, "Store.Get", "[]byte"), _StoreResult[error](results, 1, "Store.Get", "error")
+
+}
+
+func (d *StoreProxy) Put(ctx context.Context, key string, value []byte) error {
+
+	var args []any = []any{ctx, key, value}
+	results := d.invocationHandler(&d.invocations[2], args)
+	_StoreCheckResults(results, 1, "Store.Put")
+	return _StoreResult[error](results, 0, "Store.Put", "error")
+
+}
+
+func (d *StoreProxy) Read(p []byte) (int, error) {
+
+	var args []any = []any{p}
+	results := d.invocationHandler(&d.invocations[3], args)
+	_StoreCheckResults(results, 2, "Store.Read")
+	return _StoreResult[int](results, 0, "Store.Read", "int"), _StoreResult[error](results, 1, "Store.Read", "error")
+
+}
+
+// _StoreProxyMethods describes the methods of StoreProxy, in the order of its invocations.
+var _StoreProxyMethods = [...]_StoreMethod{
+	{
+		methodName:   "Close",
+		receiver:     "Store",
+		resultTypes:  []string{"error"},
+		errorIndex:   0,
+		contextIndex: -1,
+		position:     "testfile.go:9",
+	},
+	{
+		methodName:   "Get",
+		receiver:     "Store",
+		paramNames:   []string{"ctx", "key"},
+		paramTypes:   []string{"context.Context", "string"},
+		resultTypes:  []string{"[]byte", "error"},
+		errorIndex:   1,
+		contextIndex: 0,
+		position:     "testfile.go:15",
+	},
+	{
+		methodName:   "Put",
+		receiver:     "Store",
+		paramNames:   []string{"ctx", "key", "value"},
+		paramTypes:   []string{"context.Context", "string", "[]byte"},
+		resultTypes:  []string{"error"},
+		errorIndex:   0,
+		contextIndex: 0,
+		position:     "testfile.go:16",
+	},
+	{
+		methodName:   "Read",
+		receiver:     "Store",
+		paramNames:   []string{"p"},
+		paramTypes:   []string{"[]byte"},
+		resultTypes:  []string{"int", "error"},
+		errorIndex:   1,
+		contextIndex: -1,
+		position:     "io.go:87",
+	},
+}
+
+// _StoreProxyInvocation binds the descriptor of a method to the delegate of a proxy, so that proxied calls don't
+// allocate it.
+type _StoreProxyInvocation struct {
+	*_StoreMethod
+	delegate Store
+	invoke   func(*_StoreProxyInvocation, []any) []any
+}
+
+func (i *_StoreProxyInvocation) Invoke(args []any) []any { return i.invoke(i, args) }
+
+func (d *_StoreProxyInvocation) invokeClose(args []any) []any {
+	result0 := d.delegate.Close()
+	return []any{result0}
+}
+
+func (d *_StoreProxyInvocation) invokeGet(args []any) []any {
+	result0, result1 := d.delegate.Get(args[0].(context.Context), args[1].(string))
+	return []any{result0, result1}
+}
+
+func (d *_StoreProxyInvocation) invokePut(args []any) []any {
+	result0 := d.delegate.Put(args[0].(context.Context), args[1].(string), args[2].([]byte))
+	return []any{result0}
+}
+
+func (d *_StoreProxyInvocation) invokeRead(args []any) []any {
+	result0, result1 := d.delegate.Read(args[0].([]byte))
+	return []any{result0, result1}
+}
+
+func NewStoreProxy(delegate Store, invocationHandler func(method interface {
+	Package() string
+	Receiver() string
+	Name() string
+	Invoke(args []any) []any
+}, args []any) (retVals []any)) *StoreProxy {
+	if invocationHandler == nil {
+		invocationHandler = func(method interface {
+			Package() string
+			Receiver() string
+			Name() string
+			Invoke(args []any) []any
+		}, args []any) []any {
+			return method.Invoke(args)
+		}
+	}
+
+	return &StoreProxy{
+		delegate:          delegate,
+		invocationHandler: invocationHandler,
+		invocations: []_StoreProxyInvocation{
+			{&_StoreProxyMethods[0], delegate, (*_StoreProxyInvocation).invokeClose},
+			{&_StoreProxyMethods[1], delegate, (*_StoreProxyInvocation).invokeGet},
+			{&_StoreProxyMethods[2], delegate, (*_StoreProxyInvocation).invokePut},
+			{&_StoreProxyMethods[3], delegate, (*_StoreProxyInvocation).invokeRead},
+		},
+	}
+}
+
+// _StoreMethod describes a proxied method. Descriptors are package-level values, shared by every proxy, so they
+// return copies of their slices and maps, which handlers may modify.
+type _StoreMethod struct {
+	methodName   string
+	receiver     string
+	tags         map[string]string
+	paramNames   []string
+	paramTypes   []string
+	resultTypes  []string
+	variadic     bool
+	errorIndex   int
+	contextIndex int
+	position     string
+}
+
+func (m *_StoreMethod) Name() string { return m.methodName }
+
+func (m *_StoreMethod) Receiver() string { return m.receiver }
+
+func (m *_StoreMethod) Package() string { return "test" }
+
+// Tags returns the tags of the method, set by //proxy:tag directives or a tags file. Invocation handlers can read them
+// with a type assertion to interface { Tags() map[string]string }.
+func (m *_StoreMethod) Tags() map[string]string {
+	if m.tags == nil {
+		return nil
+	}
+	tags := make(map[string]string, len(m.tags))
+	for key, value := range m.tags {
+		tags[key] = value
+	}
+	return tags
+}
+
+// ParamNames returns the names of the method's parameters, in the order of args. Unnamed parameters are named after their
+// position, as in p0.
+func (m *_StoreMethod) ParamNames() []string { return append([]string(nil), m.paramNames...) }
+
+// ParamTypes returns the declared types of the method's parameters, such as ...string for a variadic parameter, which
+// is passed in args as a slice.
+func (m *_StoreMethod) ParamTypes() []string { return append([]string(nil), m.paramTypes...) }
+
+// ResultTypes returns the declared types of the method's results.
+func (m *_StoreMethod) ResultTypes() []string { return append([]string(nil), m.resultTypes...) }
+
+// Variadic reports whether the method's last parameter is variadic.
+func (m *_StoreMethod) Variadic() bool { return m.variadic }
+
+// ErrorIndex returns the index of the method's trailing error result, or -1 if it doesn't return an error.
+func (m *_StoreMethod) ErrorIndex() int { return m.errorIndex }
+
+// ContextIndex returns the index of the method's leading context.Context parameter, or -1 if it doesn't take one.
+func (m *_StoreMethod) ContextIndex() int { return m.contextIndex }
+
+// Position returns the file:line of the method's declaration, or an empty string if it's unknown.
+func (m *_StoreMethod) Position() string { return m.position }
+
+func _StoreCheckResults(results []any, count int, methodName string) {
+	if len(results) != count {
+		panic(fmt.Sprintf("invocation handler returned %d results for %s, expected %d", len(results), methodName, count))
+	}
+}
+
+func _StoreResult[T any](results []any, index int, methodName string, typeName string) T {
+	// A nil result, such as a nil error, converts to the zero value of its declared type.
+	result, ok := results[index].(T)
+	if !ok && results[index] != nil {
+		panic(fmt.Sprintf("invocation handler returned %T at index %d for %s, expected %s", results[index], index, methodName, typeName))
+	}
+	return result
+}
+`,
+			expectedError: nil,
+		},
+		{
+			name: "Unnamed and blank parameters",
+			input: `package test
+
+import "context"
+
+type MyType struct {}
+
+func (m *MyType) Handle(context.Context, string) error { return nil }
+
+func (m *MyType) Blank(_ int, name string) {}
+`,
+			flags: &flags.ParsedFlags{
+				PackageName:        "test",
+				TypeNames:          []string{"MyType"},
+				PassthroughMethods: map[string]bool{},
+			},
+			expectedOutput: `package test
+
+// Code generated by Mikaël's proxy generator. DO NOT EDIT.
+
+import (
+	context "context"
+	fmt "fmt"
+)
+
+type MyTypeProxy struct {
+	delegate          *MyType
+	invocationHandler func(method interface {
+		Package() string
+		Receiver() string
+		Name() string
+		Invoke(args []any) []any
+	}, args []any) []any
+	invocations []_MyTypeProxyInvocation
+}
+
+func (d *MyTypeProxy) Handle(p0 context.Context, p1 string) error {
+
+	var args []any = []any{p0, p1}
+	results := d.invocationHandler(&d.invocations[0], args)
+	_MyTypeCheckResults(results, 1, "MyType.Handle")
+	return _MyTypeResult[error](results, 0, "MyType.Handle", "error")
+
+}
+
+func (d *MyTypeProxy) Blank(p0 int, name string) {
+
+	var args []any = []any{p0, name}
+	d.invocationHandler(&d.invocations[1], args)
+
+}
+
+// _MyTypeProxyMethods describes the methods of MyTypeProxy, in the order of its invocations.
+var _MyTypeProxyMethods = [...]_MyTypeMethod{
+	{
+		methodName:   "Handle",
+		receiver:     "*MyType",
+		paramNames:   []string{"p0", "p1"},
+		paramTypes:   []string{"context.Context", "string"},
+		resultTypes:  []string{"error"},
+		errorIndex:   0,
+		contextIndex: 0,
+		position:     "testfile.go:7",
+	},
+	{
+		methodName:   "Blank",
+		receiver:     "*MyType",
+		paramNames:   []string{"p0", "name"},
+		paramTypes:   []string{"int", "string"},
+		errorIndex:   -1,
+		contextIndex: -1,
+		position:     "testfile.go:9",
+	},
+}
+
+// _MyTypeProxyInvocation binds the descriptor of a method to the delegate of a proxy, so that proxied calls don't
+// allocate it.
+type _MyTypeProxyInvocation struct {
+	*_MyTypeMethod
+	delegate *MyType
+	invoke   func(*_MyTypeProxyInvocation, []any) []any
+}
+
+func (i *_MyTypeProxyInvocation) Invoke(args []any) []any { return i.invoke(i, args) }
+
+func (d *_MyTypeProxyInvocation) invokeHandle(args []any) []any {
+	result0 := d.delegate.Handle(args[0].(context.Context), args[1].(string))
+	return []any{result0}
+}
+
+func (d *_MyTypeProxyInvocation) invokeBlank(args []any) []any {
+	d.delegate.Blank(args[0].(int), args[1].(string))
+	return []any{}
+}
+
+func NewMyTypeProxy(delegate *MyType, invocationHandler func(method interface {
+	Package() string
+	Receiver() string
+	Name() string
+	Invoke(args []any) []any
+}, args []any) (retVals []any)) *MyTypeProxy {
+	if invocationHandler == nil {
+		invocationHandler = func(method interface {
+			Package() string
+			Receiver() string
+			Name() string
+			Invoke(args []any) []any
+		}, args []any) []any {
+			return method.Invoke(args)
+		}
+	}
+
+	return &MyTypeProxy{
+		delegate:          delegate,
+		invocationHandler: invocationHandler,
+		invocations: []_MyTypeProxyInvocation{
+			{&_MyTypeProxyMethods[0], delegate, (*_MyTypeProxyInvocation).invokeHandle},
+			{&_MyTypeProxyMethods[1], delegate, (*_MyTypeProxyInvocation).invokeBlank},
+		},
+	}
+}
+
+// _MyTypeMethod describes a proxied method. Descriptors are package-level values, shared by every proxy, so they
+// return copies of their slices and maps, which handlers may modify.
+type _MyTypeMethod struct {
+	methodName   string
+	receiver     string
+	tags         map[string]string
+	paramNames   []string
+	paramTypes   []string
+	resultTypes  []string
+	variadic     bool
+	errorIndex   int
+	contextIndex int
+	position     string
+}
+
+func (m *_MyTypeMethod) Name() string { return m.methodName }
+
+func (m *_MyTypeMethod) Receiver() string { return m.receiver }
+
+func (m *_MyTypeMethod) Package() string { return "test" }
+
+// Tags returns the tags of the method, set by //proxy:tag directives or a tags file. Invocation handlers can read them
+// with a type assertion to interface { Tags() map[string]string }.
+func (m *_MyTypeMethod) Tags() map[string]string {
+	if m.tags == nil {
+		return nil
+	}
+	tags := make(map[string]string, len(m.tags))
+	for key, value := range m.tags {
+		tags[key] = value
+	}
+	return tags
+}
+
+// ParamNames returns the names of the method's parameters, in the order of args. Unnamed parameters are named after their
+// position, as in p0.
+func (m *_MyTypeMethod) ParamNames() []string { return append([]string(nil), m.paramNames...) }
+
+// ParamTypes returns the declared types of the method's parameters, such as ...string for a variadic parameter, which
+// is passed in args as a slice.
+func (m *_MyTypeMethod) ParamTypes() []string { return append([]string(nil), m.paramTypes...) }
+
+// ResultTypes returns the declared types of the method's results.
+func (m *_MyTypeMethod) ResultTypes() []string { return append([]string(nil), m.resultTypes...) }
+
+// Variadic reports whether the method's last parameter is variadic.
+func (m *_MyTypeMethod) Variadic() bool { return m.variadic }
+
+// ErrorIndex returns the index of the method's trailing error result, or -1 if it doesn't return an error.
+func (m *_MyTypeMethod) ErrorIndex() int { return m.errorIndex }
+
+// ContextIndex returns the index of the method's leading context.Context parameter, or -1 if it doesn't take one.
+func (m *_MyTypeMethod) ContextIndex() int { return m.contextIndex }
+
+// Position returns the file:line of the method's declaration, or an empty string if it's unknown.
+func (m *_MyTypeMethod) Position() string { return m.position }
+
+func _MyTypeCheckResults(results []any, count int, methodName string) {
+	if len(results) != count {
+		panic(fmt.Sprintf("invocation handler returned %d results for %s, expected %d", len(results), methodName, count))
+	}
+}
+
+func _MyTypeResult[T any](results []any, index int, methodName string, typeName string) T {
+	// A nil result, such as a nil error, converts to the zero value of its declared type.
+	result, ok := results[index].(T)
+	if !ok && results[index] != nil {
+		panic(fmt.Sprintf("invocation handler returned %T at index %d for %s, expected %s", results[index], index, methodName, typeName))
+	}
+	return result
+}
+`,
+			expectedError: nil,
+		},
+		{
+			name: "Passthrough methods with arguments",
+			input: `package test
+
+import "context"
+
+type MyType struct {}
+
+func (m *MyType) Get(ctx context.Context, id string) (string, error) { return id, nil }
+
+func (m *MyType) Logf(format string, values ...any) {}
+`,
+			flags: &flags.ParsedFlags{
+				PackageName:        "test",
+				TypeNames:          []string{"MyType"},
+				PassthroughMethods: map[string]bool{"Get": true, "Logf": true},
+			},
+			expectedOutput: `package test
+
+// Code generated by Mikaël's proxy generator. DO NOT EDIT.
+
+import (
+	context "context"
+	fmt "fmt"
+)
+
+type MyTypeProxy struct {
+	delegate          *MyType
+	invocationHandler func(method interface {
+		Package() string
+		Receiver() string
+		Name() string
+		Invoke(args []any) []any
+	}, args []any) []any
+	invocations []_MyTypeProxyInvocation
+}
+
+func (d *MyTypeProxy) Get(ctx context.Context, id string) (string, error) {
+
+	return d.delegate.Get(ctx, id)
+
+}
+
+func (d *MyTypeProxy) Logf(format string, values ...any) {
+
+	d.delegate.Logf(format, values...)
+
+}
+
+// _MyTypeProxyMethods describes the methods of MyTypeProxy, in the order of its invocations.
+var _MyTypeProxyMethods = [...]_MyTypeMethod{
+	{
+		methodName:   "Get",
+		receiver:     "*MyType",
+		paramNames:   []string{"ctx", "id"},
+		paramTypes:   []string{"context.Context", "string"},
+		resultTypes:  []string{"string", "error"},
+		errorIndex:   1,
+		contextIndex: 0,
+		position:     "testfile.go:7",
+	},
+	{
+		methodName:   "Logf",
+		receiver:     "*MyType",
+		paramNames:   []string{"format", "values"},
+		paramTypes:   []string{"string", "...any"},
+		variadic:     true,
+		errorIndex:   -1,
+		contextIndex: -1,
+		position:     "testfile.go:9",
+	},
+}
+
+// _MyTypeProxyInvocation binds the descriptor of a method to the delegate of a proxy, so that proxied calls don't
+// allocate it.
+type _MyTypeProxyInvocation struct {
+	*_MyTypeMethod
+	delegate *MyType
+	invoke   func(*_MyTypeProxyInvocation, []any) []any
+}
+
+func (i *_MyTypeProxyInvocation) Invoke(args []any) []any { return i.invoke(i, args) }
+
+func (d *_MyTypeProxyInvocation) invokeGet(args []any) []any {
+	result0, result1 := d.delegate.Get(args[0].(context.Context), args[1].(string))
+	return []any{result0, result1}
+}
+
+func (d *_MyTypeProxyInvocation) invokeLogf(args []any) []any {
+	d.delegate.Logf(args[0].(string), args[1].([]any)...)
+	return []any{}
+}
+
+func NewMyTypeProxy(delegate *MyType, invocationHandler func(method interface {
+	Package() string
+	Receiver() string
+	Name() string
+	Invoke(args []any) []any
+}, args []any) (retVals []any)) *MyTypeProxy {
+	if invocationHandler == nil {
+		invocationHandler = func(method interface {
+			Package() string
+			Receiver() string
+			Name() string
+			Invoke(args []any) []any
+		}, args []any) []any {
+			return method.Invoke(args)
+		}
+	}
+
+	return &MyTypeProxy{
+		delegate:          delegate,
+		invocationHandler: invocationHandler,
+		invocations: []_MyTypeProxyInvocation{
+			{&_MyTypeProxyMethods[0], delegate, (*_MyTypeProxyInvocation).invokeGet},
+			{&_MyTypeProxyMethods[1], delegate, (*_MyTypeProxyInvocation).invokeLogf},
+		},
+	}
+}
+
+// _MyTypeMethod describes a proxied method. Descriptors are package-level values, shared by every proxy, so they
+// return copies of their slices and maps, which handlers may modify.
+type _MyTypeMethod struct {
+	methodName   string
+	receiver     string
+	tags         map[string]string
+	paramNames   []string
+	paramTypes   []string
+	resultTypes  []string
+	variadic     bool
+	errorIndex   int
+	contextIndex int
+	position     string
+}
+
+func (m *_MyTypeMethod) Name() string { return m.methodName }
+
+func (m *_MyTypeMethod) Receiver() string { return m.receiver }
+
+func (m *_MyTypeMethod) Package() string { return "test" }
+
+// Tags returns the tags of the method, set by //proxy:tag directives or a tags file. Invocation handlers can read them
+// with a type assertion to interface { Tags() map[string]string }.
+func (m *_MyTypeMethod) Tags() map[string]string {
+	if m.tags == nil {
+		return nil
+	}
+	tags := make(map[string]string, len(m.tags))
+	for key, value := range m.tags {
+		tags[key] = value
+	}
+	return tags
+}
+
+// ParamNames returns the names of the method's parameters, in the order of args. Unnamed parameters are named after their
+// position, as in p0.
+func (m *_MyTypeMethod) ParamNames() []string { return append([]string(nil), m.paramNames...) }
+
+// ParamTypes returns the declared types of the method's parameters, such as ...string for a variadic parameter, which
+// is passed in args as a slice.
+func (m *_MyTypeMethod) ParamTypes() []string { return append([]string(nil), m.paramTypes...) }
+
+// ResultTypes returns the declared types of the method's results.
+func (m *_MyTypeMethod) ResultTypes() []string { return append([]string(nil), m.resultTypes...) }
+
+// Variadic reports whether the method's last parameter is variadic.
+func (m *_MyTypeMethod) Variadic() bool { return m.variadic }
+
+// ErrorIndex returns the index of the method's trailing error result, or -1 if it doesn't return an error.
+func (m *_MyTypeMethod) ErrorIndex() int { return m.errorIndex }
+
+// ContextIndex returns the index of the method's leading context.Context parameter, or -1 if it doesn't take one.
+func (m *_MyTypeMethod) ContextIndex() int { return m.contextIndex }
+
+// Position returns the file:line of the method's declaration, or an empty string if it's unknown.
+func (m *_MyTypeMethod) Position() string { return m.position }
+
+func _MyTypeCheckResults(results []any, count int, methodName string) {
+	if len(results) != count {
+		panic(fmt.Sprintf("invocation handler returned %d results for %s, expected %d", len(results), methodName, count))
+	}
+}
+
+func _MyTypeResult[T any](results []any, index int, methodName string, typeName string) T {
+	// A nil result, such as a nil error, converts to the zero value of its declared type.
+	result, ok := results[index].(T)
+	if !ok && results[index] != nil {
+		panic(fmt.Sprintf("invocation handler returned %T at index %d for %s, expected %s", results[index], index, methodName, typeName))
+	}
+	return result
+}
+`,
+			expectedError: nil,
+		},
+		{
+			name: "Unexported methods",
+			input: `package test
+
+type MyType struct {}
+
+func (m *MyType) Exported() {}
+
+func (m *MyType) unexported() {}
+`,
+			flags: &flags.ParsedFlags{
+				PackageName:        "test",
+				TypeNames:          []string{"MyType"},
+				PassthroughMethods: map[string]bool{},
+				IncludeUnexported:  true,
+			},
+			expectedOutput: `package test
+
+// Code generated by Mikaël's proxy generator. DO NOT EDIT.
+
+import (
+	fmt "fmt"
+)
+
+type MyTypeProxy struct {
+	delegate          *MyType
+	invocationHandler func(method interface {
+		Package() string
+		Receiver() string
+		Name() string
+		Invoke(args []any) []any
+	}, args []any) []any
+	invocations []_MyTypeProxyInvocation
+}
+
+func (d *MyTypeProxy) Exported() {
+
+	var args []any
+	d.invocationHandler(&d.invocations[0], args)
+
+}
+
+func (d *MyTypeProxy) unexported() {
+
+	var args []any
+	d.invocationHandler(&d.invocations[1], args)
+
+}
+
+// _MyTypeProxyMethods describes the methods of MyTypeProxy, in the order of its invocations.
+var _MyTypeProxyMethods = [...]_MyTypeMethod{
+	{
+		methodName:   "Exported",
+		receiver:     "*MyType",
+		errorIndex:   -1,
+		contextIndex: -1,
+		position:     "testfile.go:5",
+	},
+	{
+		methodName:   "unexported",
+		receiver:     "*MyType",
+		errorIndex:   -1,
+		contextIndex: -1,
+		position:     "testfile.go:7",
+	},
+}
+
+// _MyTypeProxyInvocation binds the descriptor of a method to the delegate of a proxy, so that proxied calls don't
+// allocate it.
+type _MyTypeProxyInvocation struct {
+	*_MyTypeMethod
+	delegate *MyType
+	invoke   func(*_MyTypeProxyInvocation, []any) []any
+}
+
+func (i *_MyTypeProxyInvocation) Invoke(args []any) []any { return i.invoke(i, args) }
+
+func (d *_MyTypeProxyInvocation) invokeExported(args []any) []any {
+	d.delegate.Exported()
+	return []any{}
+}
+
+func (d *_MyTypeProxyInvocation) invokeunexported(args []any) []any {
+	d.delegate.unexported()
+	return []any{}
+}
+
+func NewMyTypeProxy(delegate *MyType, invocationHandler func(method interface {
+	Package() string
+	Receiver() string
+	Name() string
+	Invoke(args []any) []any
+}, args []any) (retVals []any)) *MyTypeProxy {
+	if invocationHandler == nil {
+		invocationHandler = func(method interface {
+			Package() string
+			Receiver() string
+			Name() string
+			Invoke(args []any) []any
+		}, args []any) []any {
+			return method.Invoke(args)
+		}
+	}
+
+	return &MyTypeProxy{
+		delegate:          delegate,
+		invocationHandler: invocationHandler,
+		invocations: []_MyTypeProxyInvocation{
+			{&_MyTypeProxyMethods[0], delegate, (*_MyTypeProxyInvocation).invokeExported},
+			{&_MyTypeProxyMethods[1], delegate, (*_MyTypeProxyInvocation).invokeunexported},
+		},
+	}
+}
+
+// _MyTypeMethod describes a proxied method. Descriptors are package-level values, shared by every proxy, so they
+// return copies of their slices and maps, which handlers may modify.
+type _MyTypeMethod struct {
+	methodName   string
+	receiver     string
+	tags         map[string]string
+	paramNames   []string
+	paramTypes   []string
+	resultTypes  []string
+	variadic     bool
+	errorIndex   int
+	contextIndex int
+	position     string
+}
+
+func (m *_MyTypeMethod) Name() string { return m.methodName }
+
+func (m *_MyTypeMethod) Receiver() string { return m.receiver }
+
+func (m *_MyTypeMethod) Package() string { return "test" }
+
+// Tags returns the tags of the method, set by //proxy:tag directives or a tags file. Invocation handlers can read them
+// with a type assertion to interface { Tags() map[string]string }.
+func (m *_MyTypeMethod) Tags() map[string]string {
+	if m.tags == nil {
+		return nil
+	}
+	tags := make(map[string]string, len(m.tags))
+	for key, value := range m.tags {
+		tags[key] = value
+	}
+	return tags
+}
+
+// ParamNames returns the names of the method's parameters, in the order of args. Unnamed parameters are named after their
+// position, as in p0.
+func (m *_MyTypeMethod) ParamNames() []string { return append([]string(nil), m.paramNames...) }
+
+// ParamTypes returns the declared types of the method's parameters, such as ...string for a variadic parameter, which
+// is passed in args as a slice.
+func (m *_MyTypeMethod) ParamTypes() []string { return append([]string(nil), m.paramTypes...) }
+
+// ResultTypes returns the declared types of the method's results.
+func (m *_MyTypeMethod) ResultTypes() []string { return append([]string(nil), m.resultTypes...) }
+
+// Variadic reports whether the method's last parameter is variadic.
+func (m *_MyTypeMethod) Variadic() bool { return m.variadic }
+
+// ErrorIndex returns the index of the method's trailing error result, or -1 if it doesn't return an error.
+func (m *_MyTypeMethod) ErrorIndex() int { return m.errorIndex }
+
+// ContextIndex returns the index of the method's leading context.Context parameter, or -1 if it doesn't take one.
+func (m *_MyTypeMethod) ContextIndex() int { return m.contextIndex }
+
+// Position returns the file:line of the method's declaration, or an empty string if it's unknown.
+func (m *_MyTypeMethod) Position() string { return m.position }
+
+func _MyTypeCheckResults(results []any, count int, methodName string) {
+	if len(results) != count {
+		panic(fmt.Sprintf("invocation handler returned %d results for %s, expected %d", len(results), methodName, count))
+	}
+}
+
+func _MyTypeResult[T any](results []any, index int, methodName string, typeName string) T {
+	// A nil result, such as a nil error, converts to the zero value of its declared type.
+	result, ok := results[index].(T)
+	if !ok && results[index] != nil {
+		panic(fmt.Sprintf("invocation handler returned %T at index %d for %s, expected %s", results[index], index, methodName, typeName))
+	}
+	return result
+}
+`,
+			expectedError: nil,
+		},
+		{
+			name: "Collisions with generated identifiers",
+			input: `package test
+
+import (
+	"context"
+	"encoding/xml"
+)
+
+type MyType struct {}
+
+func (m *MyType) Run(args []string, method string, d int) (results int, err error) { return 0, nil }
+
+func (m *MyType) Results(result0 string, result1_ bool) (string, bool) { return "", false }
+
+func (m *MyType) Shadow(xml xml.Name, context context.Context) {}
+
+func (m *MyType) Error(error string) error { return nil }
+
+type User struct {}
+
+func (m *MyType) Get(ctx context.Context, User User, any int) User { return User }
+`,
+			flags: &flags.ParsedFlags{
+				PackageName:        "test",
+				TypeNames:          []string{"MyType"},
+				PassthroughMethods: map[string]bool{},
+			},
+			expectedOutput: `package test
+
+// Code generated by Mikaël's proxy generator. DO NOT EDIT.
+
+import (
+	context "context"
+	xml "encoding/xml"
+	fmt "fmt"
+)
+
+type MyTypeProxy struct {
+	delegate          *MyType
+	invocationHandler func(method interface {
+		Package() string
+		Receiver() string
+		Name() string
+		Invoke(args []any) []any
+	}, args []any) []any
+	invocations []_MyTypeProxyInvocation
+}
+
+func (d_ *MyTypeProxy) Run(args []string, method string, d int) (int, error) {
+
+	var args_ []any = []any{args, method, d}
+	results_ := d_.invocationHandler(&d_.invocations[0], args_)
+	_MyTypeCheckResults(results_, 2, "MyType.Run")
+	return _MyTypeResult[int](results_, 0, "MyType.Run", "int"), _MyTypeResult[error](results_, 1, "MyType.Run", "error")
+
+}
+
+func (d *MyTypeProxy) Results(result0 string, result1_ bool) (string, bool) {
+
+	var args []any = []any{result0, result1_}
+	results := d.invocationHandler(&d.invocations[1], args)
+	_MyTypeCheckResults(results, 2, "MyType.Results")
+	return _MyTypeResult[string](results, 0, "MyType.Results", "string"), _MyTypeResult[bool](results, 1, "MyType.Results", "bool")
+
+}
+
+func (d *MyTypeProxy) Shadow(xml_ xml.Name, context_ context.Context) {
+
+	var args []any = []any{xml_, context_}
+	d.invocationHandler(&d.invocations[2], args)
+
+}
+
+func (d *MyTypeProxy) Error(error_ string) error {
+
+	var args []any = []any{error_}
+	results := d.invocationHandler(&d.invocations[3], args)
+	_MyTypeCheckResults(results, 1, "MyType.Error")
+	return _MyTypeResult[error](results, 0, "MyType.Error", "error")
+
+}
+
+func (d *MyTypeProxy) Get(ctx context.Context, User_ User, any_ int) User {
+
+	var args []any = []any{ctx, User_, any_}
+	results := d.invocationHandler(&d.invocations[4], args)
+	_MyTypeCheckResults(results, 1, "MyType.Get")
+	return _MyTypeResult[User](results, 0, "MyType.Get", "User")
+
+}
+
+// _MyTypeProxyMethods describes the methods of MyTypeProxy, in the order of its invocations.
+var _MyTypeProxyMethods = [...]_MyTypeMethod{
+	{
+		methodName:   "Run",
+		receiver:     "*MyType",
+		paramNames:   []string{"args", "method", "d"},
+		paramTypes:   []string{"[]string", "string", "int"},
+		resultTypes:  []string{"int", "error"},
+		errorIndex:   1,
+		contextIndex: -1,
+		position:     "testfile.go:10",
+	},
+	{
+		methodName:   "Results",
+		receiver:     "*MyType",
+		paramNames:   []string{"result0", "result1_"},
+		paramTypes:   []string{"string", "bool"},
+		resultTypes:  []string{"string", "bool"},
+		errorIndex:   -1,
+		contextIndex: -1,
+		position:     "testfile.go:12",
+	},
+	{
+		methodName:   "Shadow",
+		receiver:     "*MyType",
+		paramNames:   []string{"xml_", "context_"},
+		paramTypes:   []string{"xml.Name", "context.Context"},
+		errorIndex:   -1,
+		contextIndex: -1,
+		position:     "testfile.go:14",
+	},
+	{
+		methodName:   "Error",
+		receiver:     "*MyType",
+		paramNames:   []string{"error_"},
+		paramTypes:   []string{"string"},
+		resultTypes:  []string{"error"},
+		errorIndex:   0,
+		contextIndex: -1,
+		position:     "testfile.go:16",
+	},
+	{
+		methodName:   "Get",
+		receiver:     "*MyType",
+		paramNames:   []string{"ctx", "User_", "any_"},
+		paramTypes:   []string{"context.Context", "User", "int"},
+		resultTypes:  []string{"User"},
+		errorIndex:   -1,
+		contextIndex: 0,
+		position:     "testfile.go:20",
+	},
+}
+
+// _MyTypeProxyInvocation binds the descriptor of a method to the delegate of a proxy, so that proxied calls don't
+// allocate it.
+type _MyTypeProxyInvocation struct {
+	*_MyTypeMethod
+	delegate *MyType
+	invoke   func(*_MyTypeProxyInvocation, []any) []any
+}
+
+func (i *_MyTypeProxyInvocation) Invoke(args []any) []any { return i.invoke(i, args) }
+
+func (d_ *_MyTypeProxyInvocation) invokeRun(args_ []any) []any {
+	result0, result1 := d_.delegate.Run(args_[0].([]string), args_[1].(string), args_[2].(int))
+	return []any{result0, result1}
+}
+
+func (d *_MyTypeProxyInvocation) invokeResults(args []any) []any {
+	result_0, result_1 := d.delegate.Results(args[0].(string), args[1].(bool))
+	return []any{result_0, result_1}
+}
+
+func (d *_MyTypeProxyInvocation) invokeShadow(args []any) []any {
+	d.delegate.Shadow(args[0].(xml.Name), args[1].(context.Context))
+	return []any{}
+}
+
+func (d *_MyTypeProxyInvocation) invokeError(args []any) []any {
+	result0 := d.delegate.Error(args[0].(string))
+	return []any{result0}
+}
+
+func (d *_MyTypeProxyInvocation) invokeGet(args []any) []any {
+	result0 := d.delegate.Get(args[0].(context.Context), args[1].(User), args[2].(int))
+	return []any{result0}
+}
+
+func NewMyTypeProxy(delegate *MyType, invocationHandler func(method interface {
+	Package() string
+	Receiver() string
+	Name() string
+	Invoke(args []any) []any
+}, args []any) (retVals []any)) *MyTypeProxy {
+	if invocationHandler == nil {
+		invocationHandler = func(method interface {
+			Package() string
+			Receiver() string
+			Name() string
+			Invoke(args []any) []any
+		}, args []any) []any {
+			return method.Invoke(args)
+		}
+	}
+
+	return &MyTypeProxy{
+		delegate:          delegate,
+		invocationHandler: invocationHandler,
+		invocations: []_MyTypeProxyInvocation{
+			{&_MyTypeProxyMethods[0], delegate, (*_MyTypeProxyInvocation).invokeRun},
+			{&_MyTypeProxyMethods[1], delegate, (*_MyTypeProxyInvocation).invokeResults},
+			{&_MyTypeProxyMethods[2], delegate, (*_MyTypeProxyInvocation).invokeShadow},
+			{&_MyTypeProxyMethods[3], delegate, (*_MyTypeProxyInvocation).invokeError},
+			{&_MyTypeProxyMethods[4], delegate, (*_MyTypeProxyInvocation).invokeGet},
+		},
+	}
+}
+
+// _MyTypeMethod describes a proxied method. Descriptors are package-level values, shared by every proxy, so they
+// return copies of their slices and maps, which handlers may modify.
+type _MyTypeMethod struct {
+	methodName   string
+	receiver     string
+	tags         map[string]string
+	paramNames   []string
+	paramTypes   []string
+	resultTypes  []string
+	variadic     bool
+	errorIndex   int
+	contextIndex int
+	position     string
+}
+
+func (m *_MyTypeMethod) Name() string { return m.methodName }
+
+func (m *_MyTypeMethod) Receiver() string { return m.receiver }
+
+func (m *_MyTypeMethod) Package() string { return "test" }
+
+// Tags returns the tags of the method, set by //proxy:tag directives or a tags file. Invocation handlers can read them
+// with a type assertion to interface { Tags() map[string]string }.
+func (m *_MyTypeMethod) Tags() map[string]string {
+	if m.tags == nil {
+		return nil
+	}
+	tags := make(map[string]string, len(m.tags))
+	for key, value := range m.tags {
+		tags[key] = value
+	}
+	return tags
+}
+
+// ParamNames returns the names of the method's parameters, in the order of args. Unnamed parameters are named after their
+// position, as in p0.
+func (m *_MyTypeMethod) ParamNames() []string { return append([]string(nil), m.paramNames...) }
+
+// ParamTypes returns the declared types of the method's parameters, such as ...string for a variadic parameter, which
+// is passed in args as a slice.
+func (m *_MyTypeMethod) ParamTypes() []string { return append([]string(nil), m.paramTypes...) }
+
+// ResultTypes returns the declared types of the method's results.
+func (m *_MyTypeMethod) ResultTypes() []string { return append([]string(nil), m.resultTypes...) }
+
+// Variadic reports whether the method's last parameter is variadic.
+func (m *_MyTypeMethod) Variadic() bool { return m.variadic }
+
+// ErrorIndex returns the index of the method's trailing error result, or -1 if it doesn't return an error.
+func (m *_MyTypeMethod) ErrorIndex() int { return m.errorIndex }
+
+// ContextIndex returns the index of the method's leading context.Context parameter, or -1 if it doesn't take one.
+func (m *_MyTypeMethod) ContextIndex() int { return m.contextIndex }
+
+// Position returns the file:line of the method's declaration, or an empty string if it's unknown.
+func (m *_MyTypeMethod) Position() string { return m.position }
+
+func _MyTypeCheckResults(results []any, count int, methodName string) {
+	if len(results) != count {
+		panic(fmt.Sprintf("invocation handler returned %d results for %s, expected %d", len(results), methodName, count))
+	}
+}
+
+func _MyTypeResult[T any](results []any, index int, methodName string, typeName string) T {
+	// A nil result, such as a nil error, converts to the zero value of its declared type.
+	result, ok := results[index].(T)
+	if !ok && results[index] != nil {
+		panic(fmt.Sprintf("invocation handler returned %T at index %d for %s, expected %s", results[index], index, methodName, typeName))
+	}
+	return result
+}
+`,
+			expectedError: nil,
+		},
+		{
+			name: "Promoted methods from embedded structs",
+			input: `package test
+
+import "sync"
+
+type baseService struct {}
+
+func (b *baseService) Health() error { return nil }
+
+func (b baseService) Close() {}
+
+type MyType struct {
+	baseService
+	sync.Mutex
+}
+
+func (m *MyType) Close() {}
+`,
+			flags: &flags.ParsedFlags{
+				PackageName:        "test",
+				TypeNames:          []string{"MyType"},
+				PassthroughMethods: map[string]bool{},
+			},
+			expectedOutput: `package test
+
+// Code generated by Mikaël's proxy generator. DO NOT EDIT.
+
+import (
+	fmt "fmt"
+)
+
+type MyTypeProxy struct {
+	delegate          *MyType
+	invocationHandler func(method interface {
+		Package() string
+		Receiver() string
+		Name() string
+		Invoke(args []any) []any
+	}, args []any) []any
+	invocations []_MyTypeProxyInvocation
+}
+
+func (d *MyTypeProxy) Close() {
+
+	var args []any
+	d.invocationHandler(&d.invocations[0], args)
+
+}
+
+func (d *MyTypeProxy) Health() error {
+
+	var args []any
+	results := d.invocationHandler(&d.invocations[1], args)
+	_MyTypeCheckResults(results, 1, "MyType.Health")
+	return _MyTypeResult[error](results, 0, "MyType.Health", "error")
+
+}
+
+func (d *MyTypeProxy) Lock() {
+
+	var args []any
+	d.invocationHandler(&d.invocations[2], args)
+
+}
+
+func (d *MyTypeProxy) TryLock() bool {
+
+	var args []any
+	results := d.invocationHandler(&d.invocations[3], args)
+	_MyTypeCheckResults(results, 1, "MyType.TryLock")
+	return _MyTypeResult[bool](results, 0, "MyType.TryLock", "bool")
+
+}
+
+func (d *MyTypeProxy) Unlock() {
+
+	var args []any
+	d.invocationHandler(&d.invocations[4], args)
+
+}
+
+// _MyTypeProxyMethods describes the methods of MyTypeProxy, in the order of its invocations.
+var _MyTypeProxyMethods = [...]_MyTypeMethod{
+	{
+		methodName:   "Close",
+		receiver:     "*MyType",
+		errorIndex:   -1,
+		contextIndex: -1,
+		position:     "testfile.go:16",
+	},
+	{
+		methodName:   "Health",
+		receiver:     "*baseService",
+		resultTypes:  []string{"error"},
+		errorIndex:   0,
+		contextIndex: -1,
+		position:     "testfile.go:7",
+	},
+	{
+		methodName:   "Lock",
+		receiver:     "*sync.Mutex",
+		errorIndex:   -1,
+		contextIndex: -1,
+		position:     "mutex.go:45",
+	},
+	{
+		methodName:   "TryLock",
+		receiver:     "*sync.Mutex",
+		resultTypes:  []string{"bool"},
+		errorIndex:   -1,
+		contextIndex: -1,
+		position:     "mutex.go:54",
+	},
+	{
+		methodName:   "Unlock",
+		receiver:     "*sync.Mutex",
+		errorIndex:   -1,
+		contextIndex: -1,
+		position:     "mutex.go:64",
+	},
+}
+
+// _MyTypeProxyInvocation binds the descriptor of a method to the delegate of a proxy, so that proxied calls don't
+// allocate it.
+type _MyTypeProxyInvocation struct {
+	*_MyTypeMethod
+	delegate *MyType
+	invoke   func(*_MyTypeProxyInvocation, []any) []any
+}
+
+func (i *_MyTypeProxyInvocation) Invoke(args []any) []any { return i.invoke(i, args) }
+
+func (d *_MyTypeProxyInvocation) invokeClose(args []any) []any {
+	d.delegate.Close()
+	return []any{}
+}
+
+func (d *_MyTypeProxyInvocation) invokeHealth(args []any) []any {
+	result0 := d.delegate.Health()
+	return []any{result0}
+}
+
+func (d *_MyTypeProxyInvocation) invokeLock(args []any) []any {
+	d.delegate.Lock()
+	return []any{}
+}
+
+func (d *_MyTypeProxyInvocation) invokeTryLock(args []any) []any {
+	result0 := d.delegate.TryLock()
+	return []any{result0}
+}
+
+func (d *_MyTypeProxyInvocation) invokeUnlock(args []any) []any {
+	d.delegate.Unlock()
+	return []any{}
+}
+
+func NewMyTypeProxy(delegate *MyType, invocationHandler func(method interface {
+	Package() string
+	Receiver() string
+	Name() string
+	Invoke(args []any) []any
+}, args []any) (retVals []any)) *MyTypeProxy {
+	if invocationHandler == nil {
+		invocationHandler = func(method interface {
+			Package() string
+			Receiver() string
+			Name() string
+			Invoke(args []any) []any
+		}, args []any) []any {
+			return method.Invoke(args)
+		}
+	}
+
+	return &MyTypeProxy{
+		delegate:          delegate,
+		invocationHandler: invocationHandler,
+		invocations: []_MyTypeProxyInvocation{
+			{&_MyTypeProxyMethods[0], delegate, (*_MyTypeProxyInvocation).invokeClose},
+			{&_MyTypeProxyMethods[1], delegate, (*_MyTypeProxyInvocation).invokeHealth},
+			{&_MyTypeProxyMethods[2], delegate, (*_MyTypeProxyInvocation).invokeLock},
+			{&_MyTypeProxyMethods[3], delegate, (*_MyTypeProxyInvocation).invokeTryLock},
+			{&_MyTypeProxyMethods[4], delegate, (*_MyTypeProxyInvocation).invokeUnlock},
+		},
+	}
+}
+
+// _MyTypeMethod describes a proxied method. Descriptors are package-level values, shared by every proxy, so they
+// return copies of their slices and maps, which handlers may modify.
+type _MyTypeMethod struct {
+	methodName   string
+	receiver     string
+	tags         map[string]string
+	paramNames   []string
+	paramTypes   []string
+	resultTypes  []string
+	variadic     bool
+	errorIndex   int
+	contextIndex int
+	position     string
+}
+
+func (m *_MyTypeMethod) Name() string { return m.methodName }
+
+func (m *_MyTypeMethod) Receiver() string { return m.receiver }
+
+func (m *_MyTypeMethod) Package() string { return "test" }
+
+// Tags returns the tags of the method, set by //proxy:tag directives or a tags file. Invocation handlers can read them
+// with a type assertion to interface { Tags() map[string]string }.
+func (m *_MyTypeMethod) Tags() map[string]string {
+	if m.tags == nil {
+		return nil
+	}
+	tags := make(map[string]string, len(m.tags))
+	for key, value := range m.tags {
+		tags[key] = value
+	}
+	return tags
+}
+
+// ParamNames returns the names of the method's parameters, in the order of args. Unnamed parameters are named after their
+// position, as in p0.
+func (m *_MyTypeMethod) ParamNames() []string { return append([]string(nil), m.paramNames...) }
+
+// ParamTypes returns the declared types of the method's parameters, such as ...string for a variadic parameter, which
+// is passed in args as a slice.
+func (m *_MyTypeMethod) ParamTypes() []string { return append([]string(nil), m.paramTypes...) }
+
+// ResultTypes returns the declared types of the method's results.
+func (m *_MyTypeMethod) ResultTypes() []string { return append([]string(nil), m.resultTypes...) }
+
+// Variadic reports whether the method's last parameter is variadic.
+func (m *_MyTypeMethod) Variadic() bool { return m.variadic }
+
+// ErrorIndex returns the index of the method's trailing error result, or -1 if it doesn't return an error.
+func (m *_MyTypeMethod) ErrorIndex() int { return m.errorIndex }
+
+// ContextIndex returns the index of the method's leading context.Context parameter, or -1 if it doesn't take one.
+func (m *_MyTypeMethod) ContextIndex() int { return m.contextIndex }
+
+// Position returns the file:line of the method's declaration, or an empty string if it's unknown.
+func (m *_MyTypeMethod) Position() string { return m.position }
+
+func _MyTypeCheckResults(results []any, count int, methodName string) {
+	if len(results) != count {
+		panic(fmt.Sprintf("invocation handler returned %d results for %s, expected %d", len(results), methodName, count))
+	}
+}
+
+func _MyTypeResult[T any](results []any, index int, methodName string, typeName string) T {
+	// A nil result, such as a nil error, converts to the zero value of its declared type.
+	result, ok := results[index].(T)
+	if !ok && results[index] != nil {
+		panic(fmt.Sprintf("invocation handler returned %T at index %d for %s, expected %s", results[index], index, methodName, typeName))
+	}
+	return result
+}
+`,
+			expectedError: nil,
+		},
+		{
+			name: "Value delegate",
+			input: `package test
+
+type Money struct {
+	cents int64
+}
+
+func (m Money) Cents() int64 {
+	return m.cents
+}
+
+func (m Money) Add(other Money) Money {
+	return Money{cents: m.cents + other.cents}
+}
+`,
+			flags: &flags.ParsedFlags{
+				PackageName:        "test",
+				TypeNames:          []string{"Money"},
+				PassthroughMethods: map[string]bool{},
+				DelegateKind:       "value",
+			},
+			expectedOutput: `package test
+
+// Code generated by Mikaël's proxy generator. DO NOT EDIT.
+
+import (
+	fmt "fmt"
+)
+
+type MoneyProxy struct {
+	delegate          Money
+	invocationHandler func(method interface {
+		Package() string
+		Receiver() string
+		Name() string
+		Invoke(args []any) []any
+	}, args []any) []any
+	invocations []_MoneyProxyInvocation
+}
+
+func (d MoneyProxy) Cents() int64 {
+
+	var args []any
+	results := d.invocationHandler(&d.invocations[0], args)
+	_MoneyCheckResults(results, 1, "Money.Cents")
+	return _MoneyResult[int64](results, 0, "Money.Cents", "int64")
+
+}
+
+func (d MoneyProxy) Add(other Money) Money {
+
+	var args []any = []any{other}
+	results := d.invocationHandler(&d.invocations[1], args)
+	_MoneyCheckResults(results, 1, "Money.Add")
+	return _MoneyResult[Money](results, 0, "Money.Add", "Money")
+
+}
+
+// _MoneyProxyMethods describes the methods of MoneyProxy, in the order of its invocations.
+var _MoneyProxyMethods = [...]_MoneyMethod{
+	{
+		methodName:   "Cents",
+		receiver:     "Money",
+		resultTypes:  []string{"int64"},
+		errorIndex:   -1,
+		contextIndex: -1,
+		position:     "testfile.go:7",
+	},
+	{
+		methodName:   "Add",
+		receiver:     "Money",
+		paramNames:   []string{"other"},
+		paramTypes:   []string{"Money"},
+		resultTypes:  []string{"Money"},
+		errorIndex:   -1,
+		contextIndex: -1,
+		position:     "testfile.go:11",
+	},
+}
+
+// _MoneyProxyInvocation binds the descriptor of a method to the delegate of a proxy, so that proxied calls don't
+// allocate it.
+type _MoneyProxyInvocation struct {
+	*_MoneyMethod
+	delegate Money
+	invoke   func(*_MoneyProxyInvocation, []any) []any
+}
+
+func (i *_MoneyProxyInvocation) Invoke(args []any) []any { return i.invoke(i, args) }
+
+func (d *_MoneyProxyInvocation) invokeCents(args []any) []any {
+	result0 := d.delegate.Cents()
+	return []any{result0}
+}
+
+func (d *_MoneyProxyInvocation) invokeAdd(args []any) []any {
+	result0 := d.delegate.Add(args[0].(Money))
+	return []any{result0}
+}
+
+func NewMoneyProxy(delegate Money, invocationHandler func(method interface {
+	Package() string
+	Receiver() string
+	Name() string
+	Invoke(args []any) []any
+}, args []any) (retVals []any)) MoneyProxy {
+	if invocationHandler == nil {
+		invocationHandler = func(method interface {
+			Package() string
+			Receiver() string
+			Name() string
+			Invoke(args []any) []any
+		}, args []any) []any {
+			return method.Invoke(args)
+		}
+	}
+
+	return MoneyProxy{
+		delegate:          delegate,
+		invocationHandler: invocationHandler,
+		invocations: []_MoneyProxyInvocation{
+			{&_MoneyProxyMethods[0], delegate, (*_MoneyProxyInvocation).invokeCents},
+			{&_MoneyProxyMethods[1], delegate, (*_MoneyProxyInvocation).invokeAdd},
+		},
+	}
+}
+
+// _MoneyMethod describes a proxied method. Descriptors are package-level values, shared by every proxy, so they
+// return copies of their slices and maps, which handlers may modify.
+type _MoneyMethod struct {
+	methodName   string
+	receiver     string
+	tags         map[string]string
+	paramNames   []string
+	paramTypes   []string
+	resultTypes  []string
+	variadic     bool
+	errorIndex   int
+	contextIndex int
+	position     string
+}
+
+func (m *_MoneyMethod) Name() string { return m.methodName }
+
+func (m *_MoneyMethod) Receiver() string { return m.receiver }
+
+func (m *_MoneyMethod) Package() string { return "test" }
+
+// Tags returns the tags of the method, set by //proxy:tag directives or a tags file. Invocation handlers can read them
+// with a type assertion to interface { Tags() map[string]string }.
+func (m *_MoneyMethod) Tags() map[string]string {
+	if m.tags == nil {
+		return nil
+	}
+	tags := make(map[string]string, len(m.tags))
+	for key, value := range m.tags {
+		tags[key] = value
+	}
+	return tags
+}
+
+// ParamNames returns the names of the method's parameters, in the order of args. Unnamed parameters are named after their
+// position, as in p0.
+func (m *_MoneyMethod) ParamNames() []string { return append([]string(nil), m.paramNames...) }
+
+// ParamTypes returns the declared types of the method's parameters, such as ...string for a variadic parameter, which
+// is passed in args as a slice.
+func (m *_MoneyMethod) ParamTypes() []string { return append([]string(nil), m.paramTypes...) }
+
+// ResultTypes returns the declared types of the method's results.
+func (m *_MoneyMethod) ResultTypes() []string { return append([]string(nil), m.resultTypes...) }
+
+// Variadic reports whether the method's last parameter is variadic.
+func (m *_MoneyMethod) Variadic() bool { return m.variadic }
+
+// ErrorIndex returns the index of the method's trailing error result, or -1 if it doesn't return an error.
+func (m *_MoneyMethod) ErrorIndex() int { return m.errorIndex }
+
+// ContextIndex returns the index of the method's leading context.Context parameter, or -1 if it doesn't take one.
+func (m *_MoneyMethod) ContextIndex() int { return m.contextIndex }
+
+// Position returns the file:line of the method's declaration, or an empty string if it's unknown.
+func (m *_MoneyMethod) Position() string { return m.position }
+
+func _MoneyCheckResults(results []any, count int, methodName string) {
+	if len(results) != count {
+		panic(fmt.Sprintf("invocation handler returned %d results for %s, expected %d", len(results), methodName, count))
+	}
+}
+
+func _MoneyResult[T any](results []any, index int, methodName string, typeName string) T {
+	// A nil result, such as a nil error, converts to the zero value of its declared type.
+	result, ok := results[index].(T)
+	if !ok && results[index] != nil {
+		panic(fmt.Sprintf("invocation handler returned %T at index %d for %s, expected %s", results[index], index, methodName, typeName))
+	}
+	return result
+}
+`,
+			expectedError: nil,
+		},
+		{
+			name: "Implements",
+			input: `package test
+
+type Store interface {
+	Get(key string) string
+}
+
+type MyType struct{}
+
+func (m *MyType) Get(key string) string {
+	return key
+}
+
+func (m *MyType) Close() error {
+	return nil
+}
+`,
+			flags: &flags.ParsedFlags{
+				PackageName:        "test",
+				TypeNames:          []string{"MyType"},
+				PassthroughMethods: map[string]bool{},
+				Implements:         []string{"Store", "io.Closer"},
+			},
+			expectedOutput: `package test
+
+// Code generated by Mikaël's proxy generator. DO NOT EDIT.
+
+import (
+	fmt "fmt"
+	io "io"
+)
+
+type MyTypeProxy struct {
+	delegate          *MyType
+	invocationHandler func(method interface {
+		Package() string
+		Receiver() string
+		Name() string
+		Invoke(args []any) []any
+	}, args []any) []any
+	invocations []_MyTypeProxyInvocation
+}
+
+var _ Store = (*MyTypeProxy)(nil)
+
+var _ io.Closer = (*MyTypeProxy)(nil)
+
+func (d *MyTypeProxy) Get(key string) string {
+
+	var args []any = []any{key}
+	results := d.invocationHandler(&d.invocations[0], args)
+	_MyTypeCheckResults(results, 1, "MyType.Get")
+	return _MyTypeResult[string](results, 0, "MyType.Get", "string")
+
+}
+
+func (d *MyTypeProxy) Close() error {
+
+	var args []any
+	results := d.invocationHandler(&d.invocations[1], args)
+	_MyTypeCheckResults(results, 1, "MyType.Close")
+	return _MyTypeResult[error](results, 0, "MyType.Close", "error")
+
+}
+
+// _MyTypeProxyMethods describes the methods of MyTypeProxy, in the order of its invocations.
+var _MyTypeProxyMethods = [...]_MyTypeMethod{
+	{
+		methodName:   "Get",
+		receiver:     "*MyType",
+		paramNames:   []string{"key"},
+		paramTypes:   []string{"string"},
+		resultTypes:  []string{"string"},
+		errorIndex:   -1,
+		contextIndex: -1,
+		position:     "testfile.go:9",
+	},
+	{
+		methodName:   "Close",
+		receiver:     "*MyType",
+		resultTypes:  []string{"error"},
+		errorIndex:   0,
+		contextIndex: -1,
+		position:     "testfile.go:13",
+	},
+}
+
+// _MyTypeProxyInvocation binds the descriptor of a method to the delegate of a proxy, so that proxied calls don't
+// allocate it.
+type _MyTypeProxyInvocation struct {
+	*_MyTypeMethod
+	delegate *MyType
+	invoke   func(*_MyTypeProxyInvocation, []any) []any
+}
+
+func (i *_MyTypeProxyInvocation) Invoke(args []any) []any { return i.invoke(i, args) }
+
+func (d *_MyTypeProxyInvocation) invokeGet(args []any) []any {
+	result0 := d.delegate.Get(args[0].(string))
+	return []any{result0}
+}
+
+func (d *_MyTypeProxyInvocation) invokeClose(args []any) []any {
+	result0 := d.delegate.Close()
+	return []any{result0}
+}
+
+func NewMyTypeProxy(delegate *MyType, invocationHandler func(method interface {
+	Package() string
+	Receiver() string
+	Name() string
+	Invoke(args []any) []any
+}, args []any) (retVals []any)) *MyTypeProxy {
+	if invocationHandler == nil {
+		invocationHandler = func(method interface {
+			Package() string
+			Receiver() string
+			Name() string
+			Invoke(args []any) []any
+		}, args []any) []any {
+			return method.Invoke(args)
+		}
+	}
+
+	return &MyTypeProxy{
+		delegate:          delegate,
+		invocationHandler: invocationHandler,
+		invocations: []_MyTypeProxyInvocation{
+			{&_MyTypeProxyMethods[0], delegate, (*_MyTypeProxyInvocation).invokeGet},
+			{&_MyTypeProxyMethods[1], delegate, (*_MyTypeProxyInvocation).invokeClose},
+		},
+	}
+}
+
+// _MyTypeMethod describes a proxied method. Descriptors are package-level values, shared by every proxy, so they
+// return copies of their slices and maps, which handlers may modify.
+type _MyTypeMethod struct {
+	methodName   string
+	receiver     string
+	tags         map[string]string
+	paramNames   []string
+	paramTypes   []string
+	resultTypes  []string
+	variadic     bool
+	errorIndex   int
+	contextIndex int
+	position     string
+}
+
+func (m *_MyTypeMethod) Name() string { return m.methodName }
+
+func (m *_MyTypeMethod) Receiver() string { return m.receiver }
+
+func (m *_MyTypeMethod) Package() string { return "test" }
+
+// Tags returns the tags of the method, set by //proxy:tag directives or a tags file. Invocation handlers can read them
+// with a type assertion to interface { Tags() map[string]string }.
+func (m *_MyTypeMethod) Tags() map[string]string {
+	if m.tags == nil {
+		return nil
+	}
+	tags := make(map[string]string, len(m.tags))
+	for key, value := range m.tags {
+		tags[key] = value
+	}
+	return tags
+}
+
+// ParamNames returns the names of the method's parameters, in the order of args. Unnamed parameters are named after their
+// position, as in p0.
+func (m *_MyTypeMethod) ParamNames() []string { return append([]string(nil), m.paramNames...) }
+
+// ParamTypes returns the declared types of the method's parameters, such as ...string for a variadic parameter, which
+// is passed in args as a slice.
+func (m *_MyTypeMethod) ParamTypes() []string { return append([]string(nil), m.paramTypes...) }
+
+// ResultTypes returns the declared types of the method's results.
+func (m *_MyTypeMethod) ResultTypes() []string { return append([]string(nil), m.resultTypes...) }
+
+// Variadic reports whether the method's last parameter is variadic.
+func (m *_MyTypeMethod) Variadic() bool { return m.variadic }
+
+// ErrorIndex returns the index of the method's trailing error result, or -1 if it doesn't return an error.
+func (m *_MyTypeMethod) ErrorIndex() int { return m.errorIndex }
+
+// ContextIndex returns the index of the method's leading context.Context parameter, or -1 if it doesn't take one.
+func (m *_MyTypeMethod) ContextIndex() int { return m.contextIndex }
+
+// Position returns the file:line of the method's declaration, or an empty string if it's unknown.
+func (m *_MyTypeMethod) Position() string { return m.position }
+
+func _MyTypeCheckResults(results []any, count int, methodName string) {
+	if len(results) != count {
+		panic(fmt.Sprintf("invocation handler returned %d results for %s, expected %d", len(results), methodName, count))
+	}
+}
+
+func _MyTypeResult[T any](results []any, index int, methodName string, typeName string) T {
+	// A nil result, such as a nil error, converts to the zero value of its declared type.
+	result, ok := results[index].(T)
+	if !ok && results[index] != nil {
+		panic(fmt.Sprintf("invocation handler returned %T at index %d for %s, expected %s", results[index], index, methodName, typeName))
+	}
+	return result
+}
+`,
+			expectedError: nil,
+		},
+		{
+			name: "Emit interface",
+			input: `package test
+
+import "context"
+
+type MyType struct{}
+
+// Get returns the value stored under key.
+//
+// It returns an empty string if there is none.
+func (m *MyType) Get(ctx context.Context, key string) string {
+	return key
+}
+
+func (m MyType) Keys(prefixes ...string) []string {
+	return nil
+}
+`,
+			flags: &flags.ParsedFlags{
+				PackageName:        "test",
+				TypeNames:          []string{"MyType"},
+				PassthroughMethods: map[string]bool{},
+				EmitInterface:      "Store",
+			},
+			expectedOutput: `package test
+
+// Code generated by Mikaël's proxy generator. DO NOT EDIT.
+
+import (
+	context "context"
+	fmt "fmt"
+)
+
+type MyTypeProxy struct {
+	delegate          *MyType
+	invocationHandler func(method interface {
+		Package() string
+		Receiver() string
+		Name() string
+		Invoke(args []any) []any
+	}, args []any) []any
+	invocations []_MyTypeProxyInvocation
+}
+
+// Store is the set of methods of MyType proxied by MyTypeProxy.
+type Store interface {
+	// Get returns the value stored under key.
+	//
+	// It returns an empty string if there is none.
+	Get(ctx context.Context, key string) string
+	Keys(prefixes ...string) []string
+}
+
+var _ Store = (*MyType)(nil)
+
+var _ Store = (*MyTypeProxy)(nil)
+
+func (d *MyTypeProxy) Get(ctx context.Context, key string) string {
+
+	var args []any = []any{ctx, key}
+	results := d.invocationHandler(&d.invocations[0], args)
+	_MyTypeCheckResults(results, 1, "MyType.Get")
+	return _MyTypeResult[string](results, 0, "MyType.Get", "string")
+
+}
+
+func (d *MyTypeProxy) Keys(prefixes ...string) []string {
+
+	var args []any = []any{prefixes}
+	results := d.invocationHandler(&d.invocations[1], args)
+	_MyTypeCheckResults(results, 1, "MyType.Keys")
+	return _MyTypeResult[[]string](results, 0, "MyType.Keys", "[]string")
+
+}
+
+// _MyTypeProxyMethods describes the methods of MyTypeProxy, in the order of its invocations.
+var _MyTypeProxyMethods = [...]_MyTypeMethod{
+	{
+		methodName:   "Get",
+		receiver:     "*MyType",
+		paramNames:   []string{"ctx", "key"},
+		paramTypes:   []string{"context.Context", "string"},
+		resultTypes:  []string{"string"},
+		errorIndex:   -1,
+		contextIndex: 0,
+		position:     "testfile.go:10",
+	},
+	{
+		methodName:   "Keys",
+		receiver:     "MyType",
+		paramNames:   []string{"prefixes"},
+		paramTypes:   []string{"...string"},
+		resultTypes:  []string{"[]string"},
+		variadic:     true,
+		errorIndex:   -1,
+		contextIndex: -1,
+		position:     "testfile.go:14",
+	},
+}
+
+// _MyTypeProxyInvocation binds the descriptor of a method to the delegate of a proxy, so that proxied calls don't
+// allocate it.
+type _MyTypeProxyInvocation struct {
+	*_MyTypeMethod
+	delegate *MyType
+	invoke   func(*_MyTypeProxyInvocation, []any) []any
+}
+
+func (i *_MyTypeProxyInvocation) Invoke(args []any) []any { return i.invoke(i, args) }
+
+func (d *_MyTypeProxyInvocation) invokeGet(args []any) []any {
+	result0 := d.delegate.Get(args[0].(context.Context), args[1].(string))
+	return []any{result0}
+}
+
+func (d *_MyTypeProxyInvocation) invokeKeys(args []any) []any {
+	result0 := d.delegate.Keys(args[0].([]string)...)
+	return []any{result0}
+}
+
+func NewMyTypeProxy(delegate *MyType, invocationHandler func(method interface {
+	Package() string
+	Receiver() string
+	Name() string
+	Invoke(args []any) []any
+}, args []any) (retVals []any)) *MyTypeProxy {
+	if invocationHandler == nil {
+		invocationHandler = func(method interface {
+			Package() string
+			Receiver() string
+			Name() string
+			Invoke(args []any) []any
+		}, args []any) []any {
+			return method.Invoke(args)
+		}
+	}
+
+	return &MyTypeProxy{
+		delegate:          delegate,
+		invocationHandler: invocationHandler,
+		invocations: []_MyTypeProxyInvocation{
+			{&_MyTypeProxyMethods[0], delegate, (*_MyTypeProxyInvocation).invokeGet},
+			{&_MyTypeProxyMethods[1], delegate, (*_MyTypeProxyInvocation).invokeKeys},
+		},
+	}
+}
+
+// _MyTypeMethod describes a proxied method. Descriptors are package-level values, shared by every proxy, so they
+// return copies of their slices and maps, which handlers may modify.
+type _MyTypeMethod struct {
+	methodName   string
+	receiver     string
+	tags         map[string]string
+	paramNames   []string
+	paramTypes   []string
+	resultTypes  []string
+	variadic     bool
+	errorIndex   int
+	contextIndex int
+	position     string
+}
+
+func (m *_MyTypeMethod) Name() string { return m.methodName }
+
+func (m *_MyTypeMethod) Receiver() string { return m.receiver }
+
+func (m *_MyTypeMethod) Package() string { return "test" }
+
+// Tags returns the tags of the method, set by //proxy:tag directives or a tags file. Invocation handlers can read them
+// with a type assertion to interface { Tags() map[string]string }.
+func (m *_MyTypeMethod) Tags() map[string]string {
+	if m.tags == nil {
+		return nil
+	}
+	tags := make(map[string]string, len(m.tags))
+	for key, value := range m.tags {
+		tags[key] = value
+	}
+	return tags
+}
+
+// ParamNames returns the names of the method's parameters, in the order of args. Unnamed parameters are named after their
+// position, as in p0.
+func (m *_MyTypeMethod) ParamNames() []string { return append([]string(nil), m.paramNames...) }
+
+// ParamTypes returns the declared types of the method's parameters, such as ...string for a variadic parameter, which
+// is passed in args as a slice.
+func (m *_MyTypeMethod) ParamTypes() []string { return append([]string(nil), m.paramTypes...) }
+
+// ResultTypes returns the declared types of the method's results.
+func (m *_MyTypeMethod) ResultTypes() []string { return append([]string(nil), m.resultTypes...) }
+
+// Variadic reports whether the method's last parameter is variadic.
+func (m *_MyTypeMethod) Variadic() bool { return m.variadic }
+
+// ErrorIndex returns the index of the method's trailing error result, or -1 if it doesn't return an error.
+func (m *_MyTypeMethod) ErrorIndex() int { return m.errorIndex }
+
+// ContextIndex returns the index of the method's leading context.Context parameter, or -1 if it doesn't take one.
+func (m *_MyTypeMethod) ContextIndex() int { return m.contextIndex }
+
+// Position returns the file:line of the method's declaration, or an empty string if it's unknown.
+func (m *_MyTypeMethod) Position() string { return m.position }
+
+func _MyTypeCheckResults(results []any, count int, methodName string) {
+	if len(results) != count {
+		panic(fmt.Sprintf("invocation handler returned %d results for %s, expected %d", len(results), methodName, count))
+	}
+}
+
+func _MyTypeResult[T any](results []any, index int, methodName string, typeName string) T {
+	// A nil result, such as a nil error, converts to the zero value of its declared type.
+	result, ok := results[index].(T)
+	if !ok && results[index] != nil {
+		panic(fmt.Sprintf("invocation handler returned %T at index %d for %s, expected %s", results[index], index, methodName, typeName))
+	}
+	return result
+}
+`,
+			expectedError: nil,
+		},
+		{
+			name: "Hooks mode",
+			input: `package test
+
+import "context"
+
+type User struct{}
+
+type MyType struct{}
+
+func (m *MyType) GetUser(ctx context.Context, id string) (*User, error) { return nil, nil }
+
+func (m *MyType) Count() int { return 0 }
+
+func (m *MyType) Log(format string, args ...any) {}
+
+func (m *MyType) Close() error { return nil }
+
+func (m *MyType) Health() error { return nil }
+`,
+			flags: &flags.ParsedFlags{
+				PackageName:        "test",
+				TypeNames:          []string{"MyType"},
+				PassthroughMethods: map[string]bool{"Health": true},
+				Mode:               "hooks",
+			},
+			expectedOutput: `package test
+
+// Code generated by Mikaël's proxy generator. DO NOT EDIT.
+
+import (
+	context "context"
+)
+
+// MyTypeHooks holds the hooks called by MyTypeProxy around the methods of MyType.
+//
+// Before hooks receive the arguments of a method and return them, possibly replaced, along with an error for methods
+// returning one, which the method then returns without calling the delegate. After hooks receive the arguments and
+// results of a method, and return its results, possibly replaced. Nil hooks are skipped.
+type MyTypeHooks struct {
+	BeforeGetUser func(ctx context.Context, id string) (context.Context, string, error)
+	AfterGetUser  func(ctx context.Context, id string, result0 *User, result1 error) (*User, error)
+	BeforeCount   func()
+	AfterCount    func(result0 int) int
+	BeforeLog     func(format string, args []any) (string, []any)
+	AfterLog      func(format string, args []any)
+	BeforeClose   func() error
+	AfterClose    func(result0 error) error
+}
+
+type MyTypeProxy struct {
+	delegate *MyType
+	hooks    MyTypeHooks
+}
+
+func (d *MyTypeProxy) GetUser(ctx context.Context, id string) (*User, error) {
+
+	var (
+		result0 *User
+		result1 error
+	)
+	if d.hooks.BeforeGetUser != nil {
+		ctx, id, result1 = d.hooks.BeforeGetUser(ctx, id)
+		if result1 != nil {
+			return result0, result1
+		}
+	}
+	result0, result1 = d.delegate.GetUser(ctx, id)
+	if d.hooks.AfterGetUser != nil {
+		result0, result1 = d.hooks.AfterGetUser(ctx, id, result0, result1)
+	}
+	return result0, result1
+
+}
+
+func (d *MyTypeProxy) Count() int {
+
+	var result0 int
+	if d.hooks.BeforeCount != nil {
+		d.hooks.BeforeCount()
+	}
+	result0 = d.delegate.Count()
+	if d.hooks.AfterCount != nil {
+		result0 = d.hooks.AfterCount(result0)
+	}
+	return result0
+
+}
+
+func (d *MyTypeProxy) Log(format string, args ...any) {
+
+	if d.hooks.BeforeLog != nil {
+		format, args = d.hooks.BeforeLog(format, args)
+	}
+	d.delegate.Log(format, args...)
+	if d.hooks.AfterLog != nil {
+		d.hooks.AfterLog(format, args)
+	}
+
+}
+
+func (d *MyTypeProxy) Close() error {
+
+	var result0 error
+	if d.hooks.BeforeClose != nil {
+		result0 = d.hooks.BeforeClose()
+		if result0 != nil {
+			return result0
+		}
+	}
+	result0 = d.delegate.Close()
+	if d.hooks.AfterClose != nil {
+		result0 = d.hooks.AfterClose(result0)
+	}
+	return result0
+
+}
+
+func (d *MyTypeProxy) Health() error {
+
+	return d.delegate.Health()
+
+}
+
+func NewMyTypeProxy(delegate *MyType, hooks MyTypeHooks) *MyTypeProxy {
+	return &MyTypeProxy{
+		delegate: delegate,
+		hooks:    hooks,
+	}
+}
+`,
+			expectedError: nil,
+		},
+		{
+			name: "Middleware",
+			input: `package test
+
+import "context"
+
+type User struct{}
+
+type MyType struct{}
+
+func (m *MyType) ChargeCard(ctx context.Context, amount int) error { return nil }
+
+func (m *MyType) CreateUser(name string, roles ...string) (*User, error) { return nil, nil }
+
+func (m *MyType) Reset() {}
+
+func (m *MyType) Health() error { return nil }
+`,
+			flags: &flags.ParsedFlags{
+				PackageName:        "test",
+				TypeNames:          []string{"MyType"},
+				PassthroughMethods: map[string]bool{"Health": true},
+				Middleware:         true,
+			},
+			expectedOutput: `package test
+
+// Code generated by Mikaël's proxy generator. DO NOT EDIT.
+
+import (
+	context "context"
+	fmt "fmt"
+)
+
+type MyTypeProxy struct {
+	delegate          *MyType
+	invocationHandler func(method interface {
+		Package() string
+		Receiver() string
+		Name() string
+		Invoke(args []any) []any
+	}, args []any) []any
+	invocations []_MyTypeProxyInvocation
+	chains      struct {
+		ChargeCard MyTypeChargeCardFunc
+		CreateUser MyTypeCreateUserFunc
+		Reset      MyTypeResetFunc
+	}
+}
+
+func (d *MyTypeProxy) ChargeCard(ctx context.Context, amount int) error {
+
+	if d.chains.ChargeCard != nil {
+		return d.chains.ChargeCard(ctx, amount)
+	}
+	var args []any = []any{ctx, amount}
+	results := d.invocationHandler(&d.invocations[0], args)
+	_MyTypeCheckResults(results, 1, "MyType.ChargeCard")
+	return _MyTypeResult[error](results, 0, "MyType.ChargeCard", "error")
+
+}
+
+func (d *MyTypeProxy) CreateUser(name string, roles ...string) (*User, error) {
+
+	if d.chains.CreateUser != nil {
+		return d.chains.CreateUser(name, roles...)
+	}
+	var args []any = []any{name, roles}
+	results := d.invocationHandler(&d.invocations[1], args)
+	_MyTypeCheckResults(results, 2, "MyType.CreateUser")
+	return _MyTypeResult[*User](results, 0, "MyType.CreateUser", "*User"), _MyTypeResult[error](results, 1, "MyType.CreateUser", "error")
+
+}
+
+func (d *MyTypeProxy) Reset() {
+
+	if d.chains.Reset != nil {
+		d.chains.Reset()
+		return
+	}
+	var args []any
+	d.invocationHandler(&d.invocations[2], args)
+
+}
+
+func (d *MyTypeProxy) Health() error {
+
+	return d.delegate.Health()
+
+}
+
+// _MyTypeProxyMethods describes the methods of MyTypeProxy, in the order of its invocations.
+var _MyTypeProxyMethods = [...]_MyTypeMethod{
+	{
+		methodName:   "ChargeCard",
+		receiver:     "*MyType",
+		paramNames:   []string{"ctx", "amount"},
+		paramTypes:   []string{"context.Context", "int"},
+		resultTypes:  []string{"error"},
+		errorIndex:   0,
+		contextIndex: 0,
+		position:     "testfile.go:9",
+	},
+	{
+		methodName:   "CreateUser",
+		receiver:     "*MyType",
+		paramNames:   []string{"name", "roles"},
+		paramTypes:   []string{"string", "...string"},
+		resultTypes:  []string{"*User", "error"},
+		variadic:     true,
+		errorIndex:   1,
+		contextIndex: -1,
+		position:     "testfile.go:11",
+	},
+	{
+		methodName:   "Reset",
+		receiver:     "*MyType",
+		errorIndex:   -1,
+		contextIndex: -1,
+		position:     "testfile.go:13",
+	},
+	{
+		methodName:   "Health",
+		receiver:     "*MyType",
+		resultTypes:  []string{"error"},
+		errorIndex:   0,
+		contextIndex: -1,
+		position:     "testfile.go:15",
+	},
+}
+
+// _MyTypeProxyInvocation binds the descriptor of a method to the delegate of a proxy, so that proxied calls don't
+// allocate it.
+type _MyTypeProxyInvocation struct {
+	*_MyTypeMethod
+	delegate *MyType
+	invoke   func(*_MyTypeProxyInvocation, []any) []any
+}
+
+func (i *_MyTypeProxyInvocation) Invoke(args []any) []any { return i.invoke(i, args) }
+
+func (d *_MyTypeProxyInvocation) invokeChargeCard(args []any) []any {
+	result0 := d.delegate.ChargeCard(args[0].(context.Context), args[1].(int))
+	return []any{result0}
+}
+
+func (d *_MyTypeProxyInvocation) invokeCreateUser(args []any) []any {
+	result0, result1 := d.delegate.CreateUser(args[0].(string), args[1].([]string)...)
+	return []any{result0, result1}
+}
+
+func (d *_MyTypeProxyInvocation) invokeReset(args []any) []any {
+	d.delegate.Reset()
+	return []any{}
+}
+
+func (d *_MyTypeProxyInvocation) invokeHealth(args []any) []any {
+	result0 := d.delegate.Health()
+	return []any{result0}
+}
+
+// MyTypeProxyOption configures the middleware of a MyTypeProxy.
+type MyTypeProxyOption func(*_MyTypeProxyMiddleware)
+
+// _MyTypeProxyMiddleware holds the middleware of each method of MyTypeProxy, set by its options.
+type _MyTypeProxyMiddleware struct {
+	ChargeCard []MyTypeChargeCardMiddleware
+	CreateUser []MyTypeCreateUserMiddleware
+	Reset      []MyTypeResetMiddleware
+}
+
+// MyTypeChargeCardFunc is the signature of MyType.ChargeCard, through which middleware continues calls.
+type MyTypeChargeCardFunc func(ctx context.Context, amount int) error
+
+// MyTypeChargeCardMiddleware wraps calls to MyTypeProxy.ChargeCard, which it continues by calling next.
+type MyTypeChargeCardMiddleware func(next MyTypeChargeCardFunc) MyTypeChargeCardFunc
+
+// WithMyTypeChargeCardMiddleware adds middleware around calls to MyTypeProxy.ChargeCard, the first being the outermost.
+func WithMyTypeChargeCardMiddleware(middleware ...MyTypeChargeCardMiddleware) MyTypeProxyOption {
+	return func(m *_MyTypeProxyMiddleware) {
+		m.ChargeCard = append(m.ChargeCard, middleware...)
+	}
+}
+
+// MyTypeCreateUserFunc is the signature of MyType.CreateUser, through which middleware continues calls.
+type MyTypeCreateUserFunc func(name string, roles ...string) (*User, error)
+
+// MyTypeCreateUserMiddleware wraps calls to MyTypeProxy.CreateUser, which it continues by calling next.
+type MyTypeCreateUserMiddleware func(next MyTypeCreateUserFunc) MyTypeCreateUserFunc
+
+// WithMyTypeCreateUserMiddleware adds middleware around calls to MyTypeProxy.CreateUser, the first being the outermost.
+func WithMyTypeCreateUserMiddleware(middleware ...MyTypeCreateUserMiddleware) MyTypeProxyOption {
+	return func(m *_MyTypeProxyMiddleware) {
+		m.CreateUser = append(m.CreateUser, middleware...)
+	}
+}
+
+// MyTypeResetFunc is the signature of MyType.Reset, through which middleware continues calls.
+type MyTypeResetFunc func()
+
+// MyTypeResetMiddleware wraps calls to MyTypeProxy.Reset, which it continues by calling next.
+type MyTypeResetMiddleware func(next MyTypeResetFunc) MyTypeResetFunc
+
+// WithMyTypeResetMiddleware adds middleware around calls to MyTypeProxy.Reset, the first being the outermost.
+func WithMyTypeResetMiddleware(middleware ...MyTypeResetMiddleware) MyTypeProxyOption {
+	return func(m *_MyTypeProxyMiddleware) {
+		m.Reset = append(m.Reset, middleware...)
+	}
+}
+
+func NewMyTypeProxy(delegate *MyType, invocationHandler func(method interface {
+	Package() string
+	Receiver() string
+	Name() string
+	Invoke(args []any) []any
+}, args []any) (retVals []any), options ...MyTypeProxyOption) *MyTypeProxy {
+	if invocationHandler == nil {
+		invocationHandler = func(method interface {
+			Package() string
+			Receiver() string
+			Name() string
+			Invoke(args []any) []any
+		}, args []any) []any {
+			return method.Invoke(args)
+		}
+	}
+
+	proxy := MyTypeProxy{
+		delegate:          delegate,
+		invocationHandler: invocationHandler,
+		invocations: []_MyTypeProxyInvocation{
+			{&_MyTypeProxyMethods[0], delegate, (*_MyTypeProxyInvocation).invokeChargeCard},
+			{&_MyTypeProxyMethods[1], delegate, (*_MyTypeProxyInvocation).invokeCreateUser},
+			{&_MyTypeProxyMethods[2], delegate, (*_MyTypeProxyInvocation).invokeReset},
+			{&_MyTypeProxyMethods[3], delegate, (*_MyTypeProxyInvocation).invokeHealth},
+		},
+	}
+
+	var middleware _MyTypeProxyMiddleware
+	for _, option := range options {
+		option(&middleware)
+	}
+	// Middleware continues calls through a copy of the proxy without chains, which calls the invocation handler.
+	next := proxy
+	proxy.chains.ChargeCard = _MyTypeChain(middleware.ChargeCard, MyTypeChargeCardFunc(next.ChargeCard))
+	proxy.chains.CreateUser = _MyTypeChain(middleware.CreateUser, MyTypeCreateUserFunc(next.CreateUser))
+	proxy.chains.Reset = _MyTypeChain(middleware.Reset, MyTypeResetFunc(next.Reset))
+	return &proxy
+}
+
+// _MyTypeMethod describes a proxied method. Descriptors are package-level values, shared by every proxy, so they
+// return copies of their slices and maps, which handlers may modify.
+type _MyTypeMethod struct {
+	methodName   string
+	receiver     string
+	tags         map[string]string
+	paramNames   []string
+	paramTypes   []string
+	resultTypes  []string
+	variadic     bool
+	errorIndex   int
+	contextIndex int
+	position     string
+}
+
+func (m *_MyTypeMethod) Name() string { return m.methodName }
+
+func (m *_MyTypeMethod) Receiver() string { return m.receiver }
+
+func (m *_MyTypeMethod) Package() string { return "test" }
+
+// Tags returns the tags of the method, set by //proxy:tag directives or a tags file. Invocation handlers can read them
+// with a type assertion to interface { Tags() map[string]string }.
+func (m *_MyTypeMethod) Tags() map[string]string {
+	if m.tags == nil {
+		return nil
+	}
+	tags := make(map[string]string, len(m.tags))
+	for key, value := range m.tags {
+		tags[key] = value
+	}
+	return tags
+}
+
+// ParamNames returns the names of the method's parameters, in the order of args. Unnamed parameters are named after their
+// position, as in p0.
+func (m *_MyTypeMethod) ParamNames() []string { return append([]string(nil), m.paramNames...) }
+
+// ParamTypes returns the declared types of the method's parameters, such as ...string for a variadic parameter, which
+// is passed in args as a slice.
+func (m *_MyTypeMethod) ParamTypes() []string { return append([]string(nil), m.paramTypes...) }
+
+// ResultTypes returns the declared types of the method's results.
+func (m *_MyTypeMethod) ResultTypes() []string { return append([]string(nil), m.resultTypes...) }
+
+// Variadic reports whether the method's last parameter is variadic.
+func (m *_MyTypeMethod) Variadic() bool { return m.variadic }
+
+// ErrorIndex returns the index of the method's trailing error result, or -1 if it doesn't return an error.
+func (m *_MyTypeMethod) ErrorIndex() int { return m.errorIndex }
+
+// ContextIndex returns the index of the method's leading context.Context parameter, or -1 if it doesn't take one.
+func (m *_MyTypeMethod) ContextIndex() int { return m.contextIndex }
+
+// Position returns the file:line of the method's declaration, or an empty string if it's unknown.
+func (m *_MyTypeMethod) Position() string { return m.position }
+
+func _MyTypeCheckResults(results []any, count int, methodName string) {
+	if len(results) != count {
+		panic(fmt.Sprintf("invocation handler returned %d results for %s, expected %d", len(results), methodName, count))
+	}
+}
+
+func _MyTypeResult[T any](results []any, index int, methodName string, typeName string) T {
+	// A nil result, such as a nil error, converts to the zero value of its declared type.
+	result, ok := results[index].(T)
+	if !ok && results[index] != nil {
+		panic(fmt.Sprintf("invocation handler returned %T at index %d for %s, expected %s", results[index], index, methodName, typeName))
+	}
+	return result
+}
+
+// _MyTypeChain composes middleware around next, the first being the outermost. It returns nil without middleware,
+// so that calls skip the chain.
+func _MyTypeChain[F any, M ~func(F) F](middleware []M, next F) F {
+	if len(middleware) == 0 {
+		var none F
+		return none
+	}
+	for i := len(middleware) - 1; i >= 0; i-- {
+		next = middleware[i](next)
+	}
+	return next
+}
+`,
+			expectedError: nil,
+		},
+		{
+			name: "Method directives",
+			input: `package test
+
+type MyType struct{}
+
+// Get is intercepted, with tags.
+//
+//proxy:tag cache=true
+func (m *MyType) Get(key string) (string, error) { return "", nil }
+
+//proxy:passthrough
+func (m *MyType) Close() error { return nil }
+
+//proxy:skip
+func (m *MyType) Debug() string { return "" }
+`,
+			flags: &flags.ParsedFlags{
+				PackageName:        "test",
+				TypeNames:          []string{"MyType"},
+				PassthroughMethods: map[string]bool{},
+			},
+			expectedOutput: `package test
+
+// Code generated by Mikaël's proxy generator. DO NOT EDIT.
+
+import (
+	fmt "fmt"
+)
+
+type MyTypeProxy struct {
+	delegate          *MyType
+	invocationHandler func(method interface {
+		Package() string
+		Receiver() string
+		Name() string
+		Invoke(args []any) []any
+	}, args []any) []any
+	invocations []_MyTypeProxyInvocation
+}
+
+func (d *MyTypeProxy) Get(key string) (string, error) {
+
+	var args []any = []any{key}
+	results := d.invocationHandler(&d.invocations[0], args)
+	_MyTypeCheckResults(results, 2, "MyType.Get")
+	return _MyTypeResult[string](results, 0, "MyType.Get", "string"), _MyTypeResult[error](results, 1, "MyType.Get", "error")
+
+}
+
+func (d *MyTypeProxy) Close() error {
+
+	return d.delegate.Close()
+
+}
+
+// _MyTypeProxyMethods describes the methods of MyTypeProxy, in the order of its invocations.
+var _MyTypeProxyMethods = [...]_MyTypeMethod{
+	{
+		methodName:   "Get",
+		receiver:     "*MyType",
+		tags:         map[string]string{"cache": "true"},
+		paramNames:   []string{"key"},
+		paramTypes:   []string{"string"},
+		resultTypes:  []string{"string", "error"},
+		errorIndex:   1,
+		contextIndex: -1,
+		position:     "testfile.go:8",
+	},
+	{
+		methodName:   "Close",
+		receiver:     "*MyType",
+		resultTypes:  []string{"error"},
+		errorIndex:   0,
+		contextIndex: -1,
+		position:     "testfile.go:11",
+	},
+}
+
+// _MyTypeProxyInvocation binds the descriptor of a method to the delegate of a proxy, so that proxied calls don't
+// allocate it.
+type _MyTypeProxyInvocation struct {
+	*_MyTypeMethod
+	delegate *MyType
+	invoke   func(*_MyTypeProxyInvocation, []any) []any
+}
+
+func (i *_MyTypeProxyInvocation) Invoke(args []any) []any { return i.invoke(i, args) }
+
+func (d *_MyTypeProxyInvocation) invokeGet(args []any) []any {
+	result0, result1 := d.delegate.Get(args[0].(string))
+	return []any{result0, result1}
+}
+
+func (d *_MyTypeProxyInvocation) invokeClose(args []any) []any {
+	result0 := d.delegate.Close()
+	return []any{result0}
+}
+
+func NewMyTypeProxy(delegate *MyType, invocationHandler func(method interface {
+	Package() string
+	Receiver() string
+	Name() string
+	Invoke(args []any) []any
+}, args []any) (retVals []any)) *MyTypeProxy {
+	if invocationHandler == nil {
+		invocationHandler = func(method interface {
+			Package() string
+			Receiver() string
+			Name() string
+			Invoke(args []any) []any
+		}, args []any) []any {
+			return method.Invoke(args)
+		}
+	}
+
+	return &MyTypeProxy{
+		delegate:          delegate,
+		invocationHandler: invocationHandler,
+		invocations: []_MyTypeProxyInvocation{
+			{&_MyTypeProxyMethods[0], delegate, (*_MyTypeProxyInvocation).invokeGet},
+			{&_MyTypeProxyMethods[1], delegate, (*_MyTypeProxyInvocation).invokeClose},
+		},
+	}
+}
+
+// _MyTypeMethod describes a proxied method. Descriptors are package-level values, shared by every proxy, so they
+// return copies of their slices and maps, which handlers may modify.
+type _MyTypeMethod struct {
+	methodName   string
+	receiver     string
+	tags         map[string]string
+	paramNames   []string
+	paramTypes   []string
+	resultTypes  []string
+	variadic     bool
+	errorIndex   int
+	contextIndex int
+	position     string
+}
+
+func (m *_MyTypeMethod) Name() string { return m.methodName }
+
+func (m *_MyTypeMethod) Receiver() string { return m.receiver }
+
+func (m *_MyTypeMethod) Package() string { return "test" }
+
+// Tags returns the tags of the method, set by //proxy:tag directives or a tags file. Invocation handlers can read them
+// with a type assertion to interface { Tags() map[string]string }.
+func (m *_MyTypeMethod) Tags() map[string]string {
+	if m.tags == nil {
+		return nil
+	}
+	tags := make(map[string]string, len(m.tags))
+	for key, value := range m.tags {
+		tags[key] = value
+	}
+	return tags
+}
+
+// ParamNames returns the names of the method's parameters, in the order of args. Unnamed parameters are named after their
+// position, as in p0.
+func (m *_MyTypeMethod) ParamNames() []string { return append([]string(nil), m.paramNames...) }
+
+// ParamTypes returns the declared types of the method's parameters, such as ...string for a variadic parameter, which
+// is passed in args as a slice.
+func (m *_MyTypeMethod) ParamTypes() []string { return append([]string(nil), m.paramTypes...) }
+
+// ResultTypes returns the declared types of the method's results.
+func (m *_MyTypeMethod) ResultTypes() []string { return append([]string(nil), m.resultTypes...) }
+
+// Variadic reports whether the method's last parameter is variadic.
+func (m *_MyTypeMethod) Variadic() bool { return m.variadic }
+
+// ErrorIndex returns the index of the method's trailing error result, or -1 if it doesn't return an error.
+func (m *_MyTypeMethod) ErrorIndex() int { return m.errorIndex }
+
+// ContextIndex returns the index of the method's leading context.Context parameter, or -1 if it doesn't take one.
+func (m *_MyTypeMethod) ContextIndex() int { return m.contextIndex }
+
+// Position returns the file:line of the method's declaration, or an empty string if it's unknown.
+func (m *_MyTypeMethod) Position() string { return m.position }
+
+func _MyTypeCheckResults(results []any, count int, methodName string) {
+	if len(results) != count {
+		panic(fmt.Sprintf("invocation handler returned %d results for %s, expected %d", len(results), methodName, count))
+	}
+}
+
+func _MyTypeResult[T any](results []any, index int, methodName string, typeName string) T {
+	// A nil result, such as a nil error, converts to the zero value of its declared type.
+	result, ok := results[index].(T)
+	if !ok && results[index] != nil {
+		panic(fmt.Sprintf("invocation handler returned %T at index %d for %s, expected %s", results[index], index, methodName, typeName))
+	}
+	return result
+}
+`,
+			expectedError: nil,
+		},
+		{
+			name: "Skipped pointer method with auto delegate kind",
+			input: `package test
+
+type MyType struct{}
+
+func (m MyType) Get(key string) string { return "" }
+
+//proxy:skip
+func (m *MyType) Reset() {}
+`,
+			flags: &flags.ParsedFlags{
+				PackageName:        "test",
+				TypeNames:          []string{"MyType"},
+				PassthroughMethods: map[string]bool{},
+				DelegateKind:       "auto",
+			},
+			expectedOutput: `package test
+
+// Code generated by Mikaël's proxy generator. DO NOT EDIT.
+
+import (
+	fmt "fmt"
+)
+
+type MyTypeProxy struct {
+	delegate          MyType
+	invocationHandler func(method interface {
+		Package() string
+		Receiver() string
+		Name() string
+		Invoke(args []any) []any
+	}, args []any) []any
+	invocations []_MyTypeProxyInvocation
+}
+
+func (d MyTypeProxy) Get(key string) string {
+
+	var args []any = []any{key}
+	results := d.invocationHandler(&d.invocations[0], args)
+	_MyTypeCheckResults(results, 1, "MyType.Get")
+	return _MyTypeResult[string](results, 0, "MyType.Get", "string")
+
+}
+
+// _MyTypeProxyMethods describes the methods of MyTypeProxy, in the order of its invocations.
+var _MyTypeProxyMethods = [...]_MyTypeMethod{
+	{
+		methodName:   "Get",
+		receiver:     "MyType",
+		paramNames:   []string{"key"},
+		paramTypes:   []string{"string"},
+		resultTypes:  []string{"string"},
+		errorIndex:   -1,
+		contextIndex: -1,
+		position:     "testfile.go:5",
+	},
+}
+
+// _MyTypeProxyInvocation binds the descriptor of a method to the delegate of a proxy, so that proxied calls don't
+// allocate it.
+type _MyTypeProxyInvocation struct {
+	*_MyTypeMethod
+	delegate MyType
+	invoke   func(*_MyTypeProxyInvocation, []any) []any
+}
+
+func (i *_MyTypeProxyInvocation) Invoke(args []any) []any { return i.invoke(i, args) }
+
+func (d *_MyTypeProxyInvocation) invokeGet(args []any) []any {
+	result0 := d.delegate.Get(args[0].(string))
+	return []any{result0}
+}
+
+func NewMyTypeProxy(delegate MyType, invocationHandler func(method interface {
+	Package() string
+	Receiver() string
+	Name() string
+	Invoke(args []any) []any
+}, args []any) (retVals []any)) MyTypeProxy {
+	if invocationHandler == nil {
+		invocationHandler = func(method interface {
+			Package() string
+			Receiver() string
+			Name() string
+			Invoke(args []any) []any
+		}, args []any) []any {
+			return method.Invoke(args)
+		}
+	}
+
+	return MyTypeProxy{
+		delegate:          delegate,
+		invocationHandler: invocationHandler,
+		invocations: []_MyTypeProxyInvocation{
+			{&_MyTypeProxyMethods[0], delegate, (*_MyTypeProxyInvocation).invokeGet},
+		},
+	}
+}
+
+// _MyTypeMethod describes a proxied method. Descriptors are package-level values, shared by every proxy, so they
+// return copies of their slices and maps, which handlers may modify.
+type _MyTypeMethod struct {
+	methodName   string
+	receiver     string
+	tags         map[string]string
+	paramNames   []string
+	paramTypes   []string
+	resultTypes  []string
+	variadic     bool
+	errorIndex   int
+	contextIndex int
+	position     string
+}
+
+func (m *_MyTypeMethod) Name() string { return m.methodName }
+
+func (m *_MyTypeMethod) Receiver() string { return m.receiver }
+
+func (m *_MyTypeMethod) Package() string { return "test" }
+
+// Tags returns the tags of the method, set by //proxy:tag directives or a tags file. Invocation handlers can read them
+// with a type assertion to interface { Tags() map[string]string }.
+func (m *_MyTypeMethod) Tags() map[string]string {
+	if m.tags == nil {
+		return nil
+	}
+	tags := make(map[string]string, len(m.tags))
+	for key, value := range m.tags {
+		tags[key] = value
+	}
+	return tags
+}
+
+// ParamNames returns the names of the method's parameters, in the order of args. Unnamed parameters are named after their
+// position, as in p0.
+func (m *_MyTypeMethod) ParamNames() []string { return append([]string(nil), m.paramNames...) }
+
+// ParamTypes returns the declared types of the method's parameters, such as ...string for a variadic parameter, which
+// is passed in args as a slice.
+func (m *_MyTypeMethod) ParamTypes() []string { return append([]string(nil), m.paramTypes...) }
+
+// ResultTypes returns the declared types of the method's results.
+func (m *_MyTypeMethod) ResultTypes() []string { return append([]string(nil), m.resultTypes...) }
+
+// Variadic reports whether the method's last parameter is variadic.
+func (m *_MyTypeMethod) Variadic() bool { return m.variadic }
+
+// ErrorIndex returns the index of the method's trailing error result, or -1 if it doesn't return an error.
+func (m *_MyTypeMethod) ErrorIndex() int { return m.errorIndex }
+
+// ContextIndex returns the index of the method's leading context.Context parameter, or -1 if it doesn't take one.
+func (m *_MyTypeMethod) ContextIndex() int { return m.contextIndex }
+
+// Position returns the file:line of the method's declaration, or an empty string if it's unknown.
+func (m *_MyTypeMethod) Position() string { return m.position }
+
+func _MyTypeCheckResults(results []any, count int, methodName string) {
+	if len(results) != count {
+		panic(fmt.Sprintf("invocation handler returned %d results for %s, expected %d", len(results), methodName, count))
+	}
+}
+
+func _MyTypeResult[T any](results []any, index int, methodName string, typeName string) T {
+	// A nil result, such as a nil error, converts to the zero value of its declared type.
+	result, ok := results[index].(T)
+	if !ok && results[index] != nil {
+		panic(fmt.Sprintf("invocation handler returned %T at index %d for %s, expected %s", results[index], index, methodName, typeName))
+	}
+	return result
+}
+`,
+			expectedError: nil,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockFH := &mockFileHandler{data: make(map[string][]byte)}
+
+			// Write the input file.
+			mockFH.data["testfile.go"] = []byte(tc.input)
+
+			g, err := new(mockFH, tc.flags)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			err = g.Run()
+			if tc.expectedError == nil && err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			} else if tc.expectedError != nil && err == nil {
+				t.Fatal("Expected an error, got nil")
+			} else if tc.expectedError != nil && err != nil && err.Error() != tc.expectedError.Error() {
+				t.Fatalf("Expected error message '%s', got '%s'", tc.expectedError.Error(), err.Error())
+			}
+
+			if err == nil {
+				generatedFileName := fmt.Sprintf("%s_proxy_gen.go", tc.flags.TypeNames[0])
+				output := string(mockFH.data[generatedFileName])
+				if output != tc.expectedOutput {
+					t.Errorf("Generated code does not match the expected output.\nExpected:\n%s\nGot:\n%s", tc.expectedOutput, output)
+				}
+			}
+		})
+	}
+}
+
+func TestGenerator_Run_PassthroughCompiles(t *testing.T) {
+	input := `package test
+
+import "context"
+
+type MyType struct{}
+
+func (m *MyType) Get(ctx context.Context, id string, opts ...string) (string, int, error) { return id, len(opts), nil }
+
+func (m *MyType) Put(ctx context.Context, id string, value []byte) error { return nil }
+`
+	mockFH := &mockFileHandler{data: map[string][]byte{"testfile.go": []byte(input)}}
+
+	g, err := new(mockFH, &flags.ParsedFlags{
+		PackageName:        "test",
+		TypeNames:          []string{"MyType"},
+		PassthroughMethods: map[string]bool{"Get": true, "Put": true},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if err := g.Run(); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	typeCheck(t, mockFH.data)
+}
+
+// typeCheck fails the test if the files, typically an input and its generated proxy, don't compile together.
+func typeCheck(t *testing.T, files map[string][]byte) {
+	t.Helper()
+
+	fset := token.NewFileSet()
+	var fileNodes []*ast.File
+	for filename, data := range files {
+		fileNode, err := parser.ParseFile(fset, filename, data, 0)
+		if err != nil {
+			t.Fatalf("Unexpected error parsing %s: %v", filename, err)
+		}
+		fileNodes = append(fileNodes, fileNode)
+	}
+
+	conf := types.Config{Importer: importer.ForCompiler(fset, "source", nil)}
+	if _, err := conf.Check("test", fset, fileNodes, nil); err != nil {
+		t.Errorf("Generated code does not compile: %v", err)
+	}
+}
+
+func TestGenerator_Run_SeveralTypes(t *testing.T) {
+	input := `package test
+
+import "context"
+
+type UserService struct{}
+
+func (s *UserService) GetUser(ctx context.Context, id string) (string, error) { return id, nil }
+
+type OrderService struct{}
+
+func (s *OrderService) CountOrders(ctx context.Context) int { return 0 }
+
+type Order struct{}
+
+func (o Order) Total() int { return 0 }
+`
+
+	testCases := []struct {
+		name          string
+		combine       bool
+		expectedFiles []string
+	}{
+		{"One file per type", false, []string{"OrderService_proxy_gen.go", "UserService_proxy_gen.go", "Order_proxy_gen.go"}},
+		{"Combined file", true, []string{"proxy_gen.go"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockFH := &mockFileHandler{data: map[string][]byte{"testfile.go": []byte(input)}}
+
+			g, err := new(mockFH, &flags.ParsedFlags{
+				PackageName:        "test",
+				TypeNames:          []string{"*Service*", "Order"},
+				PassthroughMethods: map[string]bool{},
+				Combine:            tc.combine,
+			})
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if err := g.Run(); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			for _, filename := range tc.expectedFiles {
+				if _, ok := mockFH.data[filename]; !ok {
+					t.Errorf("Expected %s to be generated", filename)
+				}
+			}
+			if len(mockFH.data) != len(tc.expectedFiles)+1 {
+				t.Errorf("Expected %d generated files, got %d", len(tc.expectedFiles), len(mockFH.data)-1)
+			}
+
+			typeCheck(t, mockFH.data)
+
+			// Running again must not proxy the generated proxies, although they match the pattern.
+			if err := g.Run(); err != nil {
+				t.Fatalf("Expected no error on second run, got %v", err)
+			}
+			if len(mockFH.data) != len(tc.expectedFiles)+1 {
+				t.Errorf("Expected %d generated files on second run, got %d", len(tc.expectedFiles), len(mockFH.data)-1)
+			}
+		})
+	}
+}
+
+// TestGenerator_Run_CombinedOutputs generates two combined files in the same package, whose helpers must not clash.
+func TestGenerator_Run_CombinedOutputs(t *testing.T) {
+	input := `package test
+
+type V struct{}
+
+func (v *V) Get() error { return nil }
+
+type I interface {
+	Put(key string)
+}
+
+type W struct{}
+
+func (w *W) Close() {}
+
+type Proxy struct{}
+
+func (p *Proxy) Run() error { return nil }
+`
+
+	testCases := []struct {
+		typeNames      []string
+		output         string
+		expectedOutput string
+	}{
+		{
+			typeNames: []string{"V", "I"},
+			output:    "a_gen.go",
+			expectedOutput: `package test
+
+// Code generated by Mikaël's proxy generator. DO NOT EDIT.
+
+import (
+	fmt "fmt"
+)
+
+type VProxy struct {
+	delegate          *V
+	invocationHandler func(method interface {
+		Package() string
+		Receiver() string
+		Name() string
+		Invoke(args []any) []any
+	}, args []any) []any
+	invocations []_VProxyInvocation
+}
+
+func (d *VProxy) Get() error {
+
+	var args []any
+	results := d.invocationHandler(&d.invocations[0], args)
+	_AGenCheckResults(results, 1, "V.Get")
+	return _AGenResult[error](results, 0, "V.Get", "error")
+
+}
+
+// _VProxyMethods describes the methods of VProxy, in the order of its invocations.
+var _VProxyMethods = [...]_AGenMethod{
+	{
+		methodName:   "Get",
+		receiver:     "*V",
+		resultTypes:  []string{"error"},
+		errorIndex:   0,
+		contextIndex: -1,
+		position:     "testfile.go:5",
+	},
+}
+
+// _VProxyInvocation binds the descriptor of a method to the delegate of a proxy, so that proxied calls don't
+// allocate it.
+type _VProxyInvocation struct {
+	*_AGenMethod
+	delegate *V
+	invoke   func(*_VProxyInvocation, []any) []any
+}
+
+func (i *_VProxyInvocation) Invoke(args []any) []any { return i.invoke(i, args) }
+
+func (d *_VProxyInvocation) invokeGet(args []any) []any {
+	result0 := d.delegate.Get()
+	return []any{result0}
+}
+
+func NewVProxy(delegate *V, invocationHandler func(method interface {
+	Package() string
+	Receiver() string
+	Name() string
+	Invoke(args []any) []any
+}, args []any) (retVals []any)) *VProxy {
+	if invocationHandler == nil {
+		invocationHandler = func(method interface {
+			Package() string
+			Receiver() string
+			Name() string
+			Invoke(args []any) []any
+		}, args []any) []any {
+			return method.Invoke(args)
+		}
+	}
+
+	return &VProxy{
+		delegate:          delegate,
+		invocationHandler: invocationHandler,
+		invocations: []_VProxyInvocation{
+			{&_VProxyMethods[0], delegate, (*_VProxyInvocation).invokeGet},
+		},
+	}
+}
+
+type IProxy struct {
+	delegate          I
+	invocationHandler func(method interface {
+		Package() string
+		Receiver() string
+		Name() string
+		Invoke(args []any) []any
+	}, args []any) []any
+	invocations []_IProxyInvocation
+}
+
+func (d *IProxy) Put(key string) {
+
+	var args []any = []any{key}
+	d.invocationHandler(&d.invocations[0], args)
+
+}
+
+// _IProxyMethods describes the methods of IProxy, in the order of its invocations.
+var _IProxyMethods = [...]_AGenMethod{
+	{
+		methodName:   "Put",
+		receiver:     "I",
+		paramNames:   []string{"key"},
+		paramTypes:   []string{"string"},
+		errorIndex:   -1,
+		contextIndex: -1,
+		position:     "testfile.go:8",
+	},
+}
+
+// _IProxyInvocation binds the descriptor of a method to the delegate of a proxy, so that proxied calls don't
+// allocate it.
+type _IProxyInvocation struct {
+	*_AGenMethod
+	delegate I
+	invoke   func(*_IProxyInvocation, []any) []any
+}
+
+func (i *_IProxyInvocation) Invoke(args []any) []any { return i.invoke(i, args) }
+
+func (d *_IProxyInvocation) invokePut(args []any) []any {
+	d.delegate.Put(args[0].(string))
+	return []any{}
+}
+
+func NewIProxy(delegate I, invocationHandler func(method interface {
+	Package() string
+	Receiver() string
+	Name() string
+	Invoke(args []any) []any
+}, args []any) (retVals []any)) *IProxy {
+	if invocationHandler == nil {
+		invocationHandler = func(method interface {
+			Package() string
+			Receiver() string
+			Name() string
+			Invoke(args []any) []any
+		}, args []any) []any {
+			return method.Invoke(args)
+		}
+	}
+
+	return &IProxy{
+		delegate:          delegate,
+		invocationHandler: invocationHandler,
+		invocations: []_IProxyInvocation{
+			{&_IProxyMethods[0], delegate, (*_IProxyInvocation).invokePut},
+		},
+	}
+}
+
+// _AGenMethod describes a proxied method. Descriptors are package-level values, shared by every proxy, so they
+// return copies of their slices and maps, which handlers may modify.
+type _AGenMethod struct {
+	methodName   string
+	receiver     string
+	tags         map[string]string
+	paramNames   []string
+	paramTypes   []string
+	resultTypes  []string
+	variadic     bool
+	errorIndex   int
+	contextIndex int
+	position     string
+}
+
+func (m *_AGenMethod) Name() string { return m.methodName }
+
+func (m *_AGenMethod) Receiver() string { return m.receiver }
+
+func (m *_AGenMethod) Package() string { return "test" }
+
+// Tags returns the tags of the method, set by //proxy:tag directives or a tags file. Invocation handlers can read them
+// with a type assertion to interface { Tags() map[string]string }.
+func (m *_AGenMethod) Tags() map[string]string {
+	if m.tags == nil {
+		return nil
+	}
+	tags := make(map[string]string, len(m.tags))
+	for key, value := range m.tags {
+		tags[key] = value
+	}
+	return tags
+}
+
+// ParamNames returns the names of the method's parameters, in the order of args. Unnamed parameters are named after their
+// position, as in p0.
+func (m *_AGenMethod) ParamNames() []string { return append([]string(nil), m.paramNames...) }
+
+// ParamTypes returns the declared types of the method's parameters, such as ...string for a variadic parameter, which
+// is passed in args as a slice.
+func (m *_AGenMethod) ParamTypes() []string { return append([]string(nil), m.paramTypes...) }
+
+// ResultTypes returns the declared types of the method's results.
+func (m *_AGenMethod) ResultTypes() []string { return append([]string(nil), m.resultTypes...) }
+
+// Variadic reports whether the method's last parameter is variadic.
+func (m *_AGenMethod) Variadic() bool { return m.variadic }
+
+// ErrorIndex returns the index of the method's trailing error result, or -1 if it doesn't return an error.
+func (m *_AGenMethod) ErrorIndex() int { return m.errorIndex }
+
+// ContextIndex returns the index of the method's leading context.Context parameter, or -1 if it doesn't take one.
+func (m *_AGenMethod) ContextIndex() int { return m.contextIndex }
+
+// Position returns the file:line of the method's declaration, or an empty string if it's unknown.
+func (m *_AGenMethod) Position() string { return m.position }
+
+func _AGenCheckResults(results []any, count int, methodName string) {
+	if len(results) != count {
+		panic(fmt.Sprintf("invocation handler returned %d results for %s, expected %d", len(results), methodName, count))
+	}
+}
+
+func _AGenResult[T any](results []any, index int, methodName string, typeName string) T {
+	// A nil result, such as a nil error, converts to the zero value of its declared type.
+	result, ok := results[index].(T)
+	if !ok && results[index] != nil {
+		panic(fmt.Sprintf("invocation handler returned %T at index %d for %s, expected %s", results[index], index, methodName, typeName))
+	}
+	return result
+}
+`,
+		},
+		{
+			typeNames: []string{"W", "Proxy"},
+			output:    "b_gen.go",
+			expectedOutput: `package test
+
+// Code generated by Mikaël's proxy generator. DO NOT EDIT.
+
+import (
+	fmt "fmt"
+)
+
+type WProxy struct {
+	delegate          *W
+	invocationHandler func(method interface {
+		Package() string
+		Receiver() string
+		Name() string
+		Invoke(args []any) []any
+	}, args []any) []any
+	invocations []_WProxyInvocation
+}
+
+func (d *WProxy) Close() {
+
+	var args []any
+	d.invocationHandler(&d.invocations[0], args)
+
+}
+
+// _WProxyMethods describes the methods of WProxy, in the order of its invocations.
+var _WProxyMethods = [...]_BGenMethod{
+	{
+		methodName:   "Close",
+		receiver:     "*W",
+		errorIndex:   -1,
+		contextIndex: -1,
+		position:     "testfile.go:13",
+	},
+}
+
+// _WProxyInvocation binds the descriptor of a method to the delegate of a proxy, so that proxied calls don't
+// allocate it.
+type _WProxyInvocation struct {
+	*_BGenMethod
+	delegate *W
+	invoke   func(*_WProxyInvocation, []any) []any
+}
+
+func (i *_WProxyInvocation) Invoke(args []any) []any { return i.invoke(i, args) }
+
+func (d *_WProxyInvocation) invokeClose(args []any) []any {
+	d.delegate.Close()
+	return []any{}
+}
+
+func NewWProxy(delegate *W, invocationHandler func(method interface {
+	Package() string
+	Receiver() string
+	Name() string
+	Invoke(args []any) []any
+}, args []any) (retVals []any)) *WProxy {
+	if invocationHandler == nil {
+		invocationHandler = func(method interface {
+			Package() string
+			Receiver() string
+			Name() string
+			Invoke(args []any) []any
+		}, args []any) []any {
+			return method.Invoke(args)
+		}
+	}
+
+	return &WProxy{
+		delegate:          delegate,
+		invocationHandler: invocationHandler,
+		invocations: []_WProxyInvocation{
+			{&_WProxyMethods[0], delegate, (*_WProxyInvocation).invokeClose},
+		},
+	}
+}
+
+type ProxyProxy struct {
+	delegate          *Proxy
+	invocationHandler func(method interface {
+		Package() string
+		Receiver() string
+		Name() string
+		Invoke(args []any) []any
+	}, args []any) []any
+	invocations []_ProxyProxyInvocation
+}
+
+func (d *ProxyProxy) Run() error {
+
+	var args []any
+	results := d.invocationHandler(&d.invocations[0], args)
+	_BGenCheckResults(results, 1, "Proxy.Run")
+	return _BGenResult[error](results, 0, "Proxy.Run", "error")
+
+}
+
+// _ProxyProxyMethods describes the methods of ProxyProxy, in the order of its invocations.
+var _ProxyProxyMethods = [...]_BGenMethod{
+	{
+		methodName:   "Run",
+		receiver:     "*Proxy",
+		resultTypes:  []string{"error"},
+		errorIndex:   0,
+		contextIndex: -1,
+		position:     "testfile.go:17",
+	},
+}
+
+// _ProxyProxyInvocation binds the descriptor of a method to the delegate of a proxy, so that proxied calls don't
+// allocate it.
+type _ProxyProxyInvocation struct {
+	*_BGenMethod
+	delegate *Proxy
+	invoke   func(*_ProxyProxyInvocation, []any) []any
+}
+
+func (i *_ProxyProxyInvocation) Invoke(args []any) []any { return i.invoke(i, args) }
+
+func (d *_ProxyProxyInvocation) invokeRun(args []any) []any {
+	result0 := d.delegate.Run()
+	return []any{result0}
+}
+
+func NewProxyProxy(delegate *Proxy, invocationHandler func(method interface {
+	Package() string
+	Receiver() string
+	Name() string
+	Invoke(args []any) []any
+}, args []any) (retVals []any)) *ProxyProxy {
+	if invocationHandler == nil {
+		invocationHandler = func(method interface {
+			Package() string
+			Receiver() string
+			Name() string
+			Invoke(args []any) []any
+		}, args []any) []any {
+			return method.Invoke(args)
+		}
+	}
+
+	return &ProxyProxy{
+		delegate:          delegate,
+		invocationHandler: invocationHandler,
+		invocations: []_ProxyProxyInvocation{
+			{&_ProxyProxyMethods[0], delegate, (*_ProxyProxyInvocation).invokeRun},
+		},
+	}
+}
+
+// _BGenMethod describes a proxied method. Descriptors are package-level values, shared by every proxy, so they
+// return copies of their slices and maps, which handlers may modify.
+type _BGenMethod struct {
+	methodName   string
+	receiver     string
+	tags         map[string]string
+	paramNames   []string
+	paramTypes   []string
+	resultTypes  []string
+	variadic     bool
+	errorIndex   int
+	contextIndex int
+	position     string
+}
+
+func (m *_BGenMethod) Name() string { return m.methodName }
+
+func (m *_BGenMethod) Receiver() string { return m.receiver }
+
+func (m *_BGenMethod) Package() string { return "test" }
+
+// Tags returns the tags of the method, set by //proxy:tag directives or a tags file. Invocation handlers can read them
+// with a type assertion to interface { Tags() map[string]string }.
+func (m *_BGenMethod) Tags() map[string]string {
+	if m.tags == nil {
+		return nil
+	}
+	tags := make(map[string]string, len(m.tags))
+	for key, value := range m.tags {
+		tags[key] = value
+	}
+	return tags
+}
+
+// ParamNames returns the names of the method's parameters, in the order of args. Unnamed parameters are named after their
+// position, as in p0.
+func (m *_BGenMethod) ParamNames() []string { return append([]string(nil), m.paramNames...) }
+
+// ParamTypes returns the declared types of the method's parameters, such as ...string for a variadic parameter, which
+// is passed in args as a slice.
+func (m *_BGenMethod) ParamTypes() []string { return append([]string(nil), m.paramTypes...) }
+
+// ResultTypes returns the declared types of the method's results.
+func (m *_BGenMethod) ResultTypes() []string { return append([]string(nil), m.resultTypes...) }
+
+// Variadic reports whether the method's last parameter is variadic.
+func (m *_BGenMethod) Variadic() bool { return m.variadic }
+
+// ErrorIndex returns the index of the method's trailing error result, or -1 if it doesn't return an error.
+func (m *_BGenMethod) ErrorIndex() int { return m.errorIndex }
+
+// ContextIndex returns the index of the method's leading context.Context parameter, or -1 if it doesn't take one.
+func (m *_BGenMethod) ContextIndex() int { return m.contextIndex }
+
+// Position returns the file:line of the method's declaration, or an empty string if it's unknown.
+func (m *_BGenMethod) Position() string { return m.position }
+
+func _BGenCheckResults(results []any, count int, methodName string) {
+	if len(results) != count {
+		panic(fmt.Sprintf("invocation handler returned %d results for %s, expected %d", len(results), methodName, count))
+	}
+}
+
+func _BGenResult[T any](results []any, index int, methodName string, typeName string) T {
+	// A nil result, such as a nil error, converts to the zero value of its declared type.
+	result, ok := results[index].(T)
+	if !ok && results[index] != nil {
+		panic(fmt.Sprintf("invocation handler returned %T at index %d for %s, expected %s", results[index], index, methodName, typeName))
+	}
+	return result
+}
+`,
+		},
+	}
+
+	mockFH := &mockFileHandler{data: map[string][]byte{"testfile.go": []byte(input)}}
+	for _, tc := range testCases {
+		g, err := new(mockFH, &flags.ParsedFlags{
+			PackageName:        "test",
+			TypeNames:          tc.typeNames,
+			PassthroughMethods: map[string]bool{},
+			Combine:            true,
+			Output:             tc.output,
+		})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if err := g.Run(); err != nil {
+			t.Fatalf("Expected no error for %s, got %v", tc.output, err)
+		}
+
+		if got := string(mockFH.data[tc.output]); got != tc.expectedOutput {
+			t.Errorf("Expected %s:\n%s\ngot:\n%s", tc.output, tc.expectedOutput, got)
+		}
+	}
+
+	typeCheck(t, mockFH.data)
+}
+
+func TestGenerator_Run_Output(t *testing.T) {
+	input := `package svc
+
+type Struct struct{}
+
+type MyType struct{}
+
+func (m *MyType) Get(aStruct Struct) (*Struct, error) { return nil, nil }
+`
+
+	testCases := []struct {
+		name          string
+		flags         *flags.ParsedFlags
+		expected      []string
+		expectedError string
+	}{
+		{
+			name: "Same package",
+			flags: &flags.ParsedFlags{
+				PackageName:        "svc",
+				TypeNames:          []string{"MyType"},
+				PassthroughMethods: map[string]bool{},
+				Output:             "-",
+			},
+			expected: []string{"package svc", "delegate          *MyType", "Get(aStruct Struct) (*Struct, error)", `return "svc"`},
+		},
+		{
+			name: "Other package",
+			flags: &flags.ParsedFlags{
+				PackageName:        "svc",
+				TypeNames:          []string{"MyType"},
+				PassthroughMethods: map[string]bool{},
+				Output:             "-",
+				OutputPackage:      "example.com/app/proxies",
+			},
+			expected: []string{"package proxies", `svc "example.com/app/svc"`, "delegate          *svc.MyType", "Get(aStruct svc.Struct) (*svc.Struct, error)", `return "svc"`},
+		},
+		{
+			name: "Several types",
+			flags: &flags.ParsedFlags{
+				PackageName:        "svc",
+				TypeNames:          []string{"MyType", "Struct"},
+				PassthroughMethods: map[string]bool{},
+				Output:             "proxies.go",
+			},
+			expectedError: "cannot write several proxies to proxies.go without --combine",
+		},
+		{
+			name: "Tags file",
+			flags: &flags.ParsedFlags{
+				PackageName:        "svc",
+				TypeNames:          []string{"MyType"},
+				PassthroughMethods: map[string]bool{},
+				Output:             "-",
+				TagsFile:           "tags.json",
+			},
+			expected: []string{`map[string]string{"cache": "true"},`},
+		},
+		{
+			name: "Missing tags file",
+			flags: &flags.ParsedFlags{
+				PackageName:        "svc",
+				TypeNames:          []string{"MyType"},
+				PassthroughMethods: map[string]bool{},
+				Output:             "-",
+				TagsFile:           "missing.json",
+			},
+			expectedError: "error reading tags file missing.json: file not found",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockFH := &mockFileHandler{
+				data: map[string][]byte{
+					"/work/app/svc/testfile.go": []byte(input),
+					"/work/app/go.mod":          []byte("module example.com/app\n\ngo 1.20\n"),
+					"tags.json":                 []byte(`{"MyType.Get": {"cache": "true"}}`),
+				},
+				GetwdFunc: func() (string, error) {
+					return "/work/app/svc", nil
+				},
+			}
+
+			g, err := new(mockFH, tc.flags)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			var stdout bytes.Buffer
+			g.stdout = &stdout
+
+			err = g.Run()
+			if tc.expectedError != "" {
+				if err == nil || err.Error() != tc.expectedError {
+					t.Errorf("Expected error '%s', got '%v'", tc.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			if len(mockFH.data) != 3 {
+				t.Errorf("Expected the proxy to be written to stdout only, got files %v", mockFH.data)
+			}
+			for _, expected := range tc.expected {
+				if !strings.Contains(stdout.String(), expected) {
+					t.Errorf("Expected output to contain '%s', got:\n%s", expected, stdout.String())
+				}
+			}
+		})
+	}
+}
+
+func TestGenerator_Run_OutputPackageName(t *testing.T) {
+	mockFH := &mockFileHandler{
+		data: map[string][]byte{
+			"/work/app/svc/testfile.go":      []byte("package svc\n\ntype MyType struct{}\n\nfunc (m *MyType) Get() {}\n"),
+			"/work/app/go.mod":               []byte("module example.com/app\n\ngo 1.20\n"),
+			"/work/app/proxies/v2/doc.go":    []byte("// Package prx holds proxies.\npackage prx\n"),
+			"/work/app/proxies/v2/x_test.go": []byte("package prx_test\n"),
+		},
+		GetwdFunc: func() (string, error) {
+			return "/work/app/svc", nil
+		},
+	}
+
+	g, err := new(mockFH, &flags.ParsedFlags{
+		PackageName:        "svc",
+		TypeNames:          []string{"MyType"},
+		PassthroughMethods: map[string]bool{},
+		Output:             "../proxies/v2/MyType_proxy_gen.go",
+		OutputPackage:      "example.com/app/proxies/v2",
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if err := g.Run(); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	output := string(mockFH.data["../proxies/v2/MyType_proxy_gen.go"])
+	if !strings.HasPrefix(output, "package prx\n") {
+		t.Errorf("Expected the proxy in the package of the existing files, got:\n%s", output)
+	}
+}
+
+func TestGenerator_Report(t *testing.T) {
+	diagnostics := Diagnostics{{File: "service.go", Line: 5, Column: 26, Method: "Get", Message: "undefined: ID"}}
+
+	testCases := []struct {
+		name     string
+		format   string
+		err      error
+		expected string
+	}{
+		{"Text", "text", diagnostics, "service.go:5:26: method Get: undefined: ID\n"},
+		{"JSON", "json", diagnostics, `[{"file":"service.go","line":5,"column":26,"method":"Get","message":"undefined: ID"}]` + "\n"},
+		{"JSON for other errors", "json", errors.New("could not find go files for package svc"), `[{"message":"could not find go files for package svc"}]` + "\n"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			parsedFlags := newMockFlags()
+			parsedFlags.DiagnosticsFormat = tc.format
+			g, err := new(&mockFileHandler{}, parsedFlags)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			var output bytes.Buffer
+			g.Report(&output, tc.err)
+			if output.String() != tc.expected {
+				t.Errorf("Expected report '%s', got '%s'", tc.expected, output.String())
 			}
 		})
 	}
@@ -0,0 +1,72 @@
+package diag
+
+import (
+	"fmt"
+	"go/token"
+	"strings"
+)
+
+// Diagnostic describes a problem preventing generation, located in the source when possible.
+type Diagnostic struct {
+	File    string `json:"file,omitempty"`
+	Line    int    `json:"line,omitempty"`
+	Column  int    `json:"column,omitempty"`
+	Method  string `json:"method,omitempty"`
+	Message string `json:"message"`
+}
+
+// New returns a diagnostic at position, which may be invalid if unknown, concerning method, if not empty.
+func New(position token.Position, method string, message string) Diagnostic {
+	return Diagnostic{
+		File:    position.Filename,
+		Line:    position.Line,
+		Column:  position.Column,
+		Method:  method,
+		Message: message,
+	}
+}
+
+// String formats the diagnostic as file:line:col: method Name: message, omitting the parts which are unknown.
+func (d Diagnostic) String() string {
+	var b strings.Builder
+	if d.File != "" {
+		b.WriteString(d.File)
+		if d.Line > 0 {
+			fmt.Fprintf(&b, ":%d:%d", d.Line, d.Column)
+		}
+		b.WriteString(": ")
+	}
+	if d.Method != "" {
+		fmt.Fprintf(&b, "method %s: ", d.Method)
+	}
+	b.WriteString(d.Message)
+	return b.String()
+}
+
+// List is an error made of diagnostics, which are reported together.
+type List []Diagnostic
+
+func (l List) Error() string {
+	lines := make([]string, 0, len(l))
+	for _, d := range l {
+		lines = append(lines, d.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Err returns the list as an error, or nil if it's empty.
+func (l List) Err() error {
+	if len(l) == 0 {
+		return nil
+	}
+	return l
+}
+
+// Append adds err to the list. Diagnostics are added as is, while other errors become diagnostics without a position.
+func (l *List) Append(err error) {
+	if list, ok := err.(List); ok {
+		*l = append(*l, list...)
+		return
+	}
+	*l = append(*l, Diagnostic{Message: err.Error()})
+}
@@ -0,0 +1,25 @@
+package diag
+
+import (
+	"errors"
+	"go/token"
+	"testing"
+)
+
+func TestList(t *testing.T) {
+	var list List
+	if list.Err() != nil {
+		t.Errorf("Expected no error for an empty list, got %v", list.Err())
+	}
+
+	list.Append(List{New(token.Position{Filename: "service.go", Line: 12, Column: 6}, "Get", "undefined: Missing")})
+	list.Append(List{New(token.Position{Filename: "service.go"}, "", "invalid file")})
+	list.Append(errors.New("could not find type declaration with name Missing"))
+
+	expected := "service.go:12:6: method Get: undefined: Missing\n" +
+		"service.go: invalid file\n" +
+		"could not find type declaration with name Missing"
+	if err := list.Err(); err == nil || err.Error() != expected {
+		t.Errorf("Expected error '%s', got '%v'", expected, err)
+	}
+}
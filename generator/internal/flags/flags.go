@@ -3,37 +3,116 @@ package flags
 import (
 	"errors"
 	"flag"
+	"fmt"
+	"go/token"
 	"os"
 	"strings"
 )
 
 type ParsedFlags struct {
-	TypeName           string
+	TypeNames          []string
 	PassthroughMethods map[string]bool
 	PackageName        string
+	IncludeUnexported  bool
+	DelegateKind       string
+	Implements         []string
+	EmitInterface      string
+	Combine            bool
+	Output             string
+	OutputPackage      string
+	DiagnosticsFormat  string
+	TagsFile           string
+	Mode               string
+	Middleware         bool
+}
+
+// stringList is a flag that can be repeated, collecting every value.
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *stringList) Set(value string) error {
+	*l = append(*l, value)
+	return nil
 }
 
 func Parse() (flags *ParsedFlags, err error) {
 	var typeName, passthroughMethodsString string
+	var includeUnexported bool
+	var delegateKind string
+	var implements stringList
+	var emitInterface string
+	var combine bool
+	var output, outputPackage string
+	var diagnosticsFormat string
+	var tagsFile string
+	var mode string
+	var middleware bool
 
-	flag.StringVar(&typeName, "type", "", "Name of the type to decorate")
+	flag.StringVar(&typeName, "type", "", "Comma-separated list of the types to decorate, which may contain wildcards, such as *Service, to match several types.")
 	flag.StringVar(&passthroughMethodsString, "passthrough-methods", "", "Comma-separated list of method names to pass through to the delegate, without interception by the invocationHandler.")
+	flag.BoolVar(&includeUnexported, "include-unexported", false, "Also proxy unexported methods, for proxies used within the package of the proxied type.")
+	flag.StringVar(&delegateKind, "delegate-kind", "pointer", "Whether the proxy holds its delegate by value or by pointer: value, pointer, or auto to hold it by value when all its methods have value receivers. Ignored for interfaces.")
+	flag.Var(&implements, "implements", "Interface that the proxy must implement, such as io.Reader or example.com/pkg.Interface. Can be repeated.")
+	flag.StringVar(&emitInterface, "emit-interface", "", "Name of an interface to generate with the proxied methods, implemented by both the proxied type and the proxy.")
+	flag.BoolVar(&combine, "combine", false, "Generate all the proxies in a single proxy_gen.go file, rather than one file per type.")
+	flag.StringVar(&output, "output", "", "Path of the generated file, or - to write it to standard output. Defaults to <Type>_proxy_gen.go, or proxy_gen.go with --combine.")
+	flag.StringVar(&outputPackage, "output-package", "", "Import path of the package to generate the proxies in, if not the package of the proxied types.")
+	flag.StringVar(&diagnosticsFormat, "diagnostics-format", "text", "Format of the problems reported when generation fails: text, or json for editor integrations.")
+	flag.StringVar(&tagsFile, "tags-file", "", `JSON file of method tags by Type.Method, such as {"MyService.GetUser": {"cache": "true"}}.`)
+	flag.StringVar(&mode, "mode", "handler", "How proxies intercept calls: handler, to call an invocation handler with the arguments as []any, or hooks, to call typed hook funcs.")
+	flag.BoolVar(&middleware, "middleware", false, "Generate typed middleware for each method, set by options of the proxy's constructor and called before the invocation handler.")
 	flag.Parse()
 
-	if typeName == "" {
-		return nil, errors.New("usage: go run github.com/LeMikaelF/proxy-generator --type <type> [--passthrough-methods <method1,method2>]")
+	typeNames := splitList(typeName)
+	if len(typeNames) == 0 {
+		return nil, errors.New("usage: go run github.com/LeMikaelF/proxy-generator --type <type1,type2> [--passthrough-methods <method1,method2>] [--include-unexported] [--delegate-kind <value|pointer|auto>] [--implements <pkg.Interface>]... [--emit-interface <name>] [--combine] [--output <file>] [--output-package <import path>] [--diagnostics-format <text|json>] [--tags-file <file>] [--mode <handler|hooks>] [--middleware]")
+	}
+
+	if delegateKind != "value" && delegateKind != "pointer" && delegateKind != "auto" {
+		return nil, fmt.Errorf("invalid delegate kind %q, expected value, pointer or auto", delegateKind)
+	}
+
+	if mode != "handler" && mode != "hooks" {
+		return nil, fmt.Errorf("invalid mode %q, expected handler or hooks", mode)
+	}
+
+	if middleware && mode == "hooks" {
+		return nil, errors.New("--middleware requires --mode handler, since hooks already intercept each method")
 	}
 
-	return &ParsedFlags{typeName, csvToMap(passthroughMethodsString), os.Getenv("GOPACKAGE")}, nil
+	if diagnosticsFormat != "text" && diagnosticsFormat != "json" {
+		return nil, fmt.Errorf("invalid diagnostics format %q, expected text or json", diagnosticsFormat)
+	}
+
+	if outputPackage != "" && output == "" {
+		return nil, errors.New("--output-package requires --output, since the proxy can't be generated alongside the proxied type")
+	}
+
+	if emitInterface != "" && !token.IsIdentifier(emitInterface) {
+		return nil, fmt.Errorf("invalid interface name %q", emitInterface)
+	}
+
+	return &ParsedFlags{typeNames, csvToMap(passthroughMethodsString), os.Getenv("GOPACKAGE"), includeUnexported, delegateKind, implements, emitInterface, combine, output, outputPackage, diagnosticsFormat, tagsFile, mode, middleware}, nil
 }
 
 func csvToMap(csv string) map[string]bool {
 	m := map[string]bool{}
-	if csv != "" {
-		slice := strings.Split(csv, ",")
-		for _, element := range slice {
-			m[element] = true
-		}
+	for _, element := range splitList(csv) {
+		m[element] = true
 	}
 	return m
 }
+
+// splitList splits a comma-separated list, trimming spaces around its elements and dropping empty ones.
+func splitList(csv string) []string {
+	var elements []string
+	for _, element := range strings.Split(csv, ",") {
+		if element = strings.TrimSpace(element); element != "" {
+			elements = append(elements, element)
+		}
+	}
+	return elements
+}
@@ -28,6 +28,11 @@ func TestCsvToMap(t *testing.T) {
 			csv:  "method1,method2,method3",
 			want: map[string]bool{"method1": true, "method2": true, "method3": true},
 		},
+		{
+			name: "Spaces and empty elements",
+			csv:  " method1, ,method2,",
+			want: map[string]bool{"method1": true, "method2": true},
+		},
 	}
 
 	for _, tt := range tests {
@@ -55,15 +60,18 @@ func TestParse(t *testing.T) {
 			name:    "No flags provided",
 			args:    []string{"cmd"},
 			want:    nil,
-			wantErr: errors.New("usage: go run github.com/LeMikaelF/proxy-generator --type <type> [--passthrough-methods <method1,method2>]"),
+			wantErr: errors.New("usage: go run github.com/LeMikaelF/proxy-generator --type <type1,type2> [--passthrough-methods <method1,method2>] [--include-unexported] [--delegate-kind <value|pointer|auto>] [--implements <pkg.Interface>]... [--emit-interface <name>] [--combine] [--output <file>] [--output-package <import path>] [--diagnostics-format <text|json>] [--tags-file <file>] [--mode <handler|hooks>] [--middleware]"),
 		},
 		{
 			name: "Only type provided",
 			args: []string{"cmd", "--type", "MyType"},
 			want: &ParsedFlags{
-				TypeName:           "MyType",
+				TypeNames:          []string{"MyType"},
 				PassthroughMethods: map[string]bool{},
 				PackageName:        os.Getenv("GOPACKAGE"),
+				DelegateKind:       "pointer",
+				DiagnosticsFormat:  "text",
+				Mode:               "handler",
 			},
 			wantErr: nil,
 		},
@@ -71,15 +79,211 @@ func TestParse(t *testing.T) {
 			name: "Type and passthrough methods provided",
 			args: []string{"cmd", "--type", "MyType", "--passthrough-methods", "method1,method2"},
 			want: &ParsedFlags{
-				TypeName: "MyType",
+				TypeNames: []string{"MyType"},
 				PassthroughMethods: map[string]bool{
 					"method1": true,
 					"method2": true,
 				},
-				PackageName: os.Getenv("GOPACKAGE"),
+				PackageName:       os.Getenv("GOPACKAGE"),
+				DelegateKind:      "pointer",
+				DiagnosticsFormat: "text",
+				Mode:              "handler",
+			},
+			wantErr: nil,
+		},
+		{
+			name: "Include unexported provided",
+			args: []string{"cmd", "--type", "MyType", "--include-unexported"},
+			want: &ParsedFlags{
+				TypeNames:          []string{"MyType"},
+				PassthroughMethods: map[string]bool{},
+				PackageName:        os.Getenv("GOPACKAGE"),
+				IncludeUnexported:  true,
+				DelegateKind:       "pointer",
+				DiagnosticsFormat:  "text",
+				Mode:               "handler",
+			},
+			wantErr: nil,
+		},
+		{
+			name: "Delegate kind provided",
+			args: []string{"cmd", "--type", "MyType", "--delegate-kind", "auto"},
+			want: &ParsedFlags{
+				TypeNames:          []string{"MyType"},
+				PassthroughMethods: map[string]bool{},
+				PackageName:        os.Getenv("GOPACKAGE"),
+				DelegateKind:       "auto",
+				DiagnosticsFormat:  "text",
+				Mode:               "handler",
+			},
+			wantErr: nil,
+		},
+		{
+			name: "Implements provided",
+			args: []string{"cmd", "--type", "MyType", "--implements", "io.Reader", "--implements", "Store"},
+			want: &ParsedFlags{
+				TypeNames:          []string{"MyType"},
+				PassthroughMethods: map[string]bool{},
+				PackageName:        os.Getenv("GOPACKAGE"),
+				DelegateKind:       "pointer",
+				DiagnosticsFormat:  "text",
+				Mode:               "handler",
+				Implements:         []string{"io.Reader", "Store"},
 			},
 			wantErr: nil,
 		},
+		{
+			name: "Emit interface provided",
+			args: []string{"cmd", "--type", "MyType", "--emit-interface", "Service"},
+			want: &ParsedFlags{
+				TypeNames:          []string{"MyType"},
+				PassthroughMethods: map[string]bool{},
+				PackageName:        os.Getenv("GOPACKAGE"),
+				DelegateKind:       "pointer",
+				DiagnosticsFormat:  "text",
+				Mode:               "handler",
+				EmitInterface:      "Service",
+			},
+			wantErr: nil,
+		},
+		{
+			name: "Several types provided",
+			args: []string{"cmd", "--type", "MyType,*Service", "--combine"},
+			want: &ParsedFlags{
+				TypeNames:          []string{"MyType", "*Service"},
+				PassthroughMethods: map[string]bool{},
+				PackageName:        os.Getenv("GOPACKAGE"),
+				DelegateKind:       "pointer",
+				DiagnosticsFormat:  "text",
+				Mode:               "handler",
+				Combine:            true,
+			},
+			wantErr: nil,
+		},
+		{
+			name: "Types with spaces and empty elements",
+			args: []string{"cmd", "--type", "MyType, *Service,"},
+			want: &ParsedFlags{
+				TypeNames:          []string{"MyType", "*Service"},
+				PassthroughMethods: map[string]bool{},
+				PackageName:        os.Getenv("GOPACKAGE"),
+				DelegateKind:       "pointer",
+				DiagnosticsFormat:  "text",
+				Mode:               "handler",
+			},
+			wantErr: nil,
+		},
+		{
+			name:    "Only empty types",
+			args:    []string{"cmd", "--type", " , "},
+			want:    nil,
+			wantErr: errors.New("usage: go run github.com/LeMikaelF/proxy-generator --type <type1,type2> [--passthrough-methods <method1,method2>] [--include-unexported] [--delegate-kind <value|pointer|auto>] [--implements <pkg.Interface>]... [--emit-interface <name>] [--combine] [--output <file>] [--output-package <import path>] [--diagnostics-format <text|json>] [--tags-file <file>] [--mode <handler|hooks>] [--middleware]"),
+		},
+		{
+			name: "Output provided",
+			args: []string{"cmd", "--type", "MyType", "--output", "-", "--output-package", "example.com/proxies"},
+			want: &ParsedFlags{
+				TypeNames:          []string{"MyType"},
+				PassthroughMethods: map[string]bool{},
+				PackageName:        os.Getenv("GOPACKAGE"),
+				DelegateKind:       "pointer",
+				DiagnosticsFormat:  "text",
+				Mode:               "handler",
+				Output:             "-",
+				OutputPackage:      "example.com/proxies",
+			},
+			wantErr: nil,
+		},
+		{
+			name: "Diagnostics format provided",
+			args: []string{"cmd", "--type", "MyType", "--diagnostics-format", "json"},
+			want: &ParsedFlags{
+				TypeNames:          []string{"MyType"},
+				PassthroughMethods: map[string]bool{},
+				PackageName:        os.Getenv("GOPACKAGE"),
+				DelegateKind:       "pointer",
+				DiagnosticsFormat:  "json",
+				Mode:               "handler",
+			},
+			wantErr: nil,
+		},
+		{
+			name: "Tags file provided",
+			args: []string{"cmd", "--type", "MyType", "--tags-file", "tags.json"},
+			want: &ParsedFlags{
+				TypeNames:          []string{"MyType"},
+				PassthroughMethods: map[string]bool{},
+				PackageName:        os.Getenv("GOPACKAGE"),
+				DelegateKind:       "pointer",
+				DiagnosticsFormat:  "text",
+				Mode:               "handler",
+				TagsFile:           "tags.json",
+			},
+			wantErr: nil,
+		},
+		{
+			name: "Mode provided",
+			args: []string{"cmd", "--type", "MyType", "--mode", "hooks"},
+			want: &ParsedFlags{
+				TypeNames:          []string{"MyType"},
+				PassthroughMethods: map[string]bool{},
+				PackageName:        os.Getenv("GOPACKAGE"),
+				DelegateKind:       "pointer",
+				DiagnosticsFormat:  "text",
+				Mode:               "hooks",
+			},
+			wantErr: nil,
+		},
+		{
+			name: "Middleware provided",
+			args: []string{"cmd", "--type", "MyType", "--middleware"},
+			want: &ParsedFlags{
+				TypeNames:          []string{"MyType"},
+				PassthroughMethods: map[string]bool{},
+				PackageName:        os.Getenv("GOPACKAGE"),
+				DelegateKind:       "pointer",
+				DiagnosticsFormat:  "text",
+				Mode:               "handler",
+				Middleware:         true,
+			},
+			wantErr: nil,
+		},
+		{
+			name:    "Middleware with hooks",
+			args:    []string{"cmd", "--type", "MyType", "--mode", "hooks", "--middleware"},
+			want:    nil,
+			wantErr: errors.New("--middleware requires --mode handler, since hooks already intercept each method"),
+		},
+		{
+			name:    "Invalid mode",
+			args:    []string{"cmd", "--type", "MyType", "--mode", "reflection"},
+			want:    nil,
+			wantErr: errors.New(`invalid mode "reflection", expected handler or hooks`),
+		},
+		{
+			name:    "Invalid diagnostics format",
+			args:    []string{"cmd", "--type", "MyType", "--diagnostics-format", "xml"},
+			want:    nil,
+			wantErr: errors.New(`invalid diagnostics format "xml", expected text or json`),
+		},
+		{
+			name:    "Output package without output",
+			args:    []string{"cmd", "--type", "MyType", "--output-package", "example.com/proxies"},
+			want:    nil,
+			wantErr: errors.New("--output-package requires --output, since the proxy can't be generated alongside the proxied type"),
+		},
+		{
+			name:    "Invalid interface name",
+			args:    []string{"cmd", "--type", "MyType", "--emit-interface", "my-service"},
+			want:    nil,
+			wantErr: errors.New(`invalid interface name "my-service"`),
+		},
+		{
+			name:    "Invalid delegate kind",
+			args:    []string{"cmd", "--type", "MyType", "--delegate-kind", "reference"},
+			want:    nil,
+			wantErr: errors.New(`invalid delegate kind "reference", expected value, pointer or auto`),
+		},
 	}
 
 	for _, tt := range tests {
@@ -103,6 +307,18 @@ func TestParse(t *testing.T) {
 	}
 }
 
+func compareSlices(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
 func compareMaps(a, b map[string]bool) bool {
 	if len(a) != len(b) {
 		return false
@@ -123,7 +339,11 @@ func compareParsedFlags(a, b *ParsedFlags) bool {
 		return false
 	}
 
-	if a.TypeName != b.TypeName || a.PackageName != b.PackageName || !compareMaps(a.PassthroughMethods, b.PassthroughMethods) {
+	if !compareSlices(a.TypeNames, b.TypeNames) || a.PackageName != b.PackageName || !compareMaps(a.PassthroughMethods, b.PassthroughMethods) ||
+		a.IncludeUnexported != b.IncludeUnexported || a.DelegateKind != b.DelegateKind ||
+		!compareSlices(a.Implements, b.Implements) || a.EmitInterface != b.EmitInterface ||
+		a.Combine != b.Combine || a.Output != b.Output || a.OutputPackage != b.OutputPackage ||
+		a.DiagnosticsFormat != b.DiagnosticsFormat || a.TagsFile != b.TagsFile || a.Mode != b.Mode || a.Middleware != b.Middleware {
 		return false
 	}
 
@@ -0,0 +1,71 @@
+package method
+
+import (
+	"fmt"
+	"go/types"
+	"strings"
+)
+
+// Identifiers are the names of the receiver and of the locals declared by a generated method.
+type Identifiers struct {
+	Receiver string
+	Args     string
+	Results  string
+	Result   string // prefix of the locals holding the delegate's results, which are suffixed by their index
+}
+
+// populateIdentifiers picks identifiers that don't collide with the method's parameters and results, the receiver's
+// type parameters, or the packages referenced by the signature.
+func populateIdentifiers(m *Method, sig *types.Signature, paramNames []string, packageNames map[string]bool) {
+	taken := make(map[string]bool)
+	for _, name := range paramNames {
+		taken[name] = true
+	}
+	for i := 0; i < sig.Results().Len(); i++ {
+		taken[sig.Results().At(i).Name()] = true
+	}
+	for _, name := range strings.Split(strings.Trim(m.ReceiverTypeArgs, "[]"), ", ") {
+		taken[name] = true
+	}
+	for name := range packageNames {
+		taken[name] = true
+	}
+
+	m.Identifiers = Identifiers{
+		Receiver: unique("d", taken),
+		Args:     unique("args", taken),
+		Results:  unique("results", taken),
+		Result:   uniquePrefix("result", sig.Results().Len(), taken),
+	}
+}
+
+// unique returns name, suffixed with underscores until it isn't taken, and marks it as taken.
+func unique(name string, taken map[string]bool) string {
+	for taken[name] {
+		name += "_"
+	}
+	taken[name] = true
+	return name
+}
+
+// uniquePrefix returns a prefix that, suffixed by any index up to count, isn't taken.
+func uniquePrefix(prefix string, count int, taken map[string]bool) string {
+	for {
+		free := true
+		for i := 0; i < count; i++ {
+			if taken[fmt.Sprintf("%s%d", prefix, i)] {
+				free = false
+				break
+			}
+		}
+		if free {
+			break
+		}
+		prefix += "_"
+	}
+
+	for i := 0; i < count; i++ {
+		taken[fmt.Sprintf("%s%d", prefix, i)] = true
+	}
+	return prefix
+}
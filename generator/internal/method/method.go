@@ -2,7 +2,8 @@ package method
 
 import (
 	"fmt"
-	"go/ast"
+	"go/types"
+	"regexp"
 	"strings"
 )
 
@@ -13,142 +14,251 @@ type Method struct {
 	ParamNames                   string
 	ParamNamesWithTypeAssertions string
 	Receiver                     string
+	ReceiverTypeArgs             string
+	ParamTypes                   []string // types of the parameters, with the variadic one as a slice
 	ResultTypes                  []string
-	ParamTypes                   []*ast.Field
-	ResultExprs                  []*ast.Field
 	Passthrough                  bool
+	Variadic                     bool
+	Identifiers                  Identifiers
+	Doc                          string            // text of the doc comment of the method's declaration, if any
+	Tags                         map[string]string // set by //proxy:tag directives
+	Signature                    Signature
+	Position                     string // file:line of the method's declaration, if known
 }
 
-func New(passThroughMethods map[string]bool, funcDecl *ast.FuncDecl, ident *ast.Ident, hasStar bool) Method {
+// Signature describes the declared signature of a method to invocation handlers. Types are named as seen from the
+// package of the proxied type, whatever the package of the generated code.
+type Signature struct {
+	ParamNames   []string
+	ParamTypes   []string // the variadic parameter's type is prefixed with ...
+	ResultTypes  []string
+	ErrorIndex   int // index of a trailing error result, or -1
+	ContextIndex int // index of a leading context.Context parameter, or -1
+}
+
+// New describes the method selected by sel in the method set of proxied. All type names are rendered with qualifier,
+// which decides how types from other packages are referenced in the generated code.
+func New(passThroughMethods map[string]bool, sel *types.Selection, proxied *types.Named, qualifier types.Qualifier) Method {
+	fn := sel.Obj().(*types.Func)
+	// The selection's signature is the one seen from the proxied type, in which promoted methods of generic embedded
+	// types refer to the proxied type's type parameters.
+	sig := sel.Type().(*types.Signature)
+	packageNames := make(map[string]bool)
+	qualifier = recordingQualifier(qualifier, packageNames)
+
 	m := Method{}
-	populatePassthrough(&m, passThroughMethods[funcDecl.Name.Name])
-	populateName(&m, funcDecl)
-	populateReceiver(&m, ident.Name, hasStar)
-	populateParameters(&m, funcDecl)
-	populateResults(&m, funcDecl)
+	populatePassthrough(&m, passThroughMethods[fn.Name()])
+	populateName(&m, fn)
+	populateReceiver(&m, sel, proxied)
+	populateResults(&m, sig, qualifier)
+	paramNames := parameterNames(sig.Params(), m.ResultTypes, qualifier, packageNames)
+	populateIdentifiers(&m, sig, paramNames, packageNames)
+	populateParameters(&m, sig, paramNames, qualifier)
+	populateSignature(&m, sig, paramNames, proxied.Obj().Pkg())
 	return m
 }
 
+// recordingQualifier wraps qualifier to record the names of the packages referenced by a method.
+func recordingQualifier(qualifier types.Qualifier, packageNames map[string]bool) types.Qualifier {
+	return func(pkg *types.Package) string {
+		name := qualifier(pkg)
+		if name != "" {
+			packageNames[name] = true
+		}
+		return name
+	}
+}
+
 func populatePassthrough(m *Method, isPassthrough bool) {
 	m.Passthrough = isPassthrough
 }
 
-func populateName(m *Method, funcDecl *ast.FuncDecl) {
-	m.Name = funcDecl.Name.Name
+func populateName(m *Method, fn *types.Func) {
+	m.Name = fn.Name()
 }
 
-func populateReceiver(m *Method, receiver string, hasStar bool) {
-	var starPrefix string
-	if hasStar {
-		starPrefix = "*"
+// populateReceiver reports the receiver declared by the method, which is the embedded type for promoted methods. For
+// interfaces, which don't declare receivers, it reports the proxied interface.
+func populateReceiver(m *Method, sel *types.Selection, proxied *types.Named) {
+	typeArgs := typeParamNames(proxied)
+	receiver := types.Type(proxied)
+
+	if _, ok := proxied.Underlying().(*types.Interface); !ok {
+		receiver = sel.Obj().Type().(*types.Signature).Recv().Type()
+		if len(sel.Index()) == 1 {
+			// Declared methods keep the type parameter names chosen by their receiver, since their signature refers to
+			// them.
+			typeArgs = receiverTypeArgs(receiver)
+		}
 	}
-	m.Receiver = fmt.Sprintf("%s%s", starPrefix, receiver)
-}
 
-func populateParameters(m *Method, funcDecl *ast.FuncDecl) {
-	if funcDecl.Type.Params != nil {
-		m.Params = parameterStrings(funcDecl.Type.Params.List)
-		m.ParamNames = fieldNamesCommaDelimited(funcDecl.Type.Params.List)
-		m.ParamNamesWithTypeAssertions = fieldNamesWithTypeAssertions(funcDecl.Type.Params.List)
-		m.ParamTypes = funcDecl.Type.Params.List
+	if len(typeArgs) > 0 {
+		m.ReceiverTypeArgs = "[" + strings.Join(typeArgs, ", ") + "]"
 	}
+
+	if receiver == proxied {
+		m.Receiver = proxied.Obj().Name() + m.ReceiverTypeArgs
+		return
+	}
+
+	// The receiver is only reported as a string, so referencing its package must not add an import.
+	m.Receiver = types.TypeString(receiver, func(pkg *types.Package) string {
+		if pkg == proxied.Obj().Pkg() {
+			return ""
+		}
+		return pkg.Name()
+	})
 }
 
-func parameterStrings(fields []*ast.Field) string {
-	parts := make([]string, 0, len(fields))
-	for _, field := range fields {
-		parts = append(parts, fmt.Sprintf("%s %s", strings.Join(names(field.Names), " "), typeName(field.Type)))
+func typeParamNames(named *types.Named) []string {
+	var names []string
+	for i := 0; i < named.TypeParams().Len(); i++ {
+		names = append(names, named.TypeParams().At(i).Obj().Name())
 	}
-	return strings.Join(parts, ",")
+	return names
 }
 
-func names(idents []*ast.Ident) []string {
-	parts := make([]string, 0, len(idents))
+func receiverTypeArgs(receiver types.Type) []string {
+	if pointer, ok := receiver.(*types.Pointer); ok {
+		receiver = pointer.Elem()
+	}
+	named, ok := receiver.(*types.Named)
+	if !ok {
+		return nil
+	}
 
-	for _, ident := range idents {
-		parts = append(parts, ident.Name)
+	var typeArgs []string
+	for i := 0; i < named.TypeArgs().Len(); i++ {
+		typeArgs = append(typeArgs, named.TypeArgs().At(i).String())
 	}
-	return parts
+	return typeArgs
 }
 
-func fieldNamesCommaDelimited(fields []*ast.Field) string {
-	names := fieldNames(fields)
-	return strings.Join(names, ",")
-}
+func populateParameters(m *Method, sig *types.Signature, names []string, qualifier types.Qualifier) {
+	params := sig.Params()
+	if params.Len() == 0 {
+		return
+	}
 
-func fieldNames(fields []*ast.Field) []string {
-	var names []string
-	for _, field := range fields {
-		for _, name := range field.Names {
-			names = append(names, name.Name)
+	parts := make([]string, 0, params.Len())
+	namesWithTypeAssertions := make([]string, 0, params.Len())
+	args := m.Identifiers.Args
+
+	for i := 0; i < params.Len(); i++ {
+		param := params.At(i)
+		typeExpr := types.TypeString(param.Type(), qualifier)
+		m.ParamTypes = append(m.ParamTypes, typeExpr)
+
+		if sig.Variadic() && i == params.Len()-1 {
+			// A variadic parameter travels through args as a slice, and is spread back when calling the delegate.
+			elemType := types.TypeString(param.Type().(*types.Slice).Elem(), qualifier)
+			parts = append(parts, fmt.Sprintf("%s ...%s", names[i], elemType))
+			namesWithTypeAssertions = append(namesWithTypeAssertions, fmt.Sprintf("%s[%d].(%s)...", args, i, typeExpr))
+		} else {
+			parts = append(parts, fmt.Sprintf("%s %s", names[i], typeExpr))
+			namesWithTypeAssertions = append(namesWithTypeAssertions, fmt.Sprintf("%s[%d].(%s)", args, i, typeExpr))
 		}
 	}
-	return names
+
+	m.Params = strings.Join(parts, ",")
+	m.ParamNames = strings.Join(names, ",")
+	m.ParamNamesWithTypeAssertions = strings.Join(namesWithTypeAssertions, ",")
+	m.Variadic = sig.Variadic()
 }
 
-func fieldNamesWithTypeAssertions(fields []*ast.Field) string {
-	var namesWithTypeAssertions []string
+// unqualifiedIdentifier matches the identifiers of a type expression which aren't qualified by a package, in its first
+// group.
+var unqualifiedIdentifier = regexp.MustCompile(`(?:^|[^.\pL\pN_])([\pL_][\pL\pN_]*)`)
 
-	for _, field := range fields {
-		typeExpr := typeName(field.Type)
-		for range field.Names {
-			namesWithTypeAssertions = append(namesWithTypeAssertions, fmt.Sprintf("args[%d].(%s)", len(namesWithTypeAssertions), typeExpr))
+// parameterNames returns the name of each parameter, synthesizing names based on their position for unnamed and blank
+// parameters, since the proxy needs to forward all of them. Parameters shadowing a package referenced by the signature,
+// a type of its results, or any, are renamed, since the generated code refers to them where the parameter is in scope.
+func parameterNames(params *types.Tuple, resultTypes []string, qualifier types.Qualifier, packageNames map[string]bool) []string {
+	shadowed := map[string]bool{"any": true}
+	for _, resultType := range resultTypes {
+		for _, match := range unqualifiedIdentifier.FindAllStringSubmatch(resultType, -1) {
+			shadowed[match[1]] = true
 		}
 	}
 
-	return strings.Join(namesWithTypeAssertions, ",")
-}
+	taken := make(map[string]bool, params.Len())
+	for i := 0; i < params.Len(); i++ {
+		// Rendering the types records every referenced package before picking names.
+		types.TypeString(params.At(i).Type(), qualifier)
+		taken[params.At(i).Name()] = true
+	}
+	for name := range packageNames {
+		shadowed[name] = true
+	}
+	for name := range shadowed {
+		taken[name] = true
+	}
 
-func populateResults(m *Method, funcDecl *ast.FuncDecl) {
-	if funcDecl.Type.Results != nil {
-		m.Results = returnParametersString(funcDecl.Type.Results.List)
-		m.ResultTypes = typeNames(funcDecl.Type.Results.List)
-		m.ResultExprs = funcDecl.Type.Results.List
+	names := make([]string, 0, params.Len())
+	for i := 0; i < params.Len(); i++ {
+		name := params.At(i).Name()
+		switch {
+		case name == "" || name == "_":
+			name = unique(fmt.Sprintf("p%d", i), taken)
+		case shadowed[name]:
+			name = unique(name, taken)
+		}
+		names = append(names, name)
 	}
+	return names
 }
 
-func returnParametersString(fields []*ast.Field) string {
-	parts := make([]string, 0, len(fields))
-	for _, field := range fields {
-		parts = append(parts, typeName(field.Type))
+func populateResults(m *Method, sig *types.Signature, qualifier types.Qualifier) {
+	results := sig.Results()
+	if results.Len() == 0 {
+		return
 	}
 
-	joined := strings.Join(parts, ",")
-
-	if len(fields) > 1 {
-		joined = "(" + joined + ")"
+	m.ResultTypes = make([]string, 0, results.Len())
+	for i := 0; i < results.Len(); i++ {
+		m.ResultTypes = append(m.ResultTypes, types.TypeString(results.At(i).Type(), qualifier))
 	}
 
-	return joined
+	m.Results = strings.Join(m.ResultTypes, ",")
+	if len(m.ResultTypes) > 1 {
+		m.Results = "(" + m.Results + ")"
+	}
 }
 
-func typeNames(idents []*ast.Field) []string {
-	typeNames := make([]string, 0, len(idents))
+// populateSignature describes the signature of the method to invocation handlers. Its types are rendered without
+// recording their packages, since they're only reported as strings.
+func populateSignature(m *Method, sig *types.Signature, paramNames []string, self *types.Package) {
+	qualifier := func(pkg *types.Package) string {
+		if pkg == self {
+			return ""
+		}
+		return pkg.Name()
+	}
 
-	for _, ident := range idents {
-		typeNames = append(typeNames, typeName(ident.Type))
+	m.Signature = Signature{ErrorIndex: -1, ContextIndex: -1}
+	params := sig.Params()
+	for i := 0; i < params.Len(); i++ {
+		m.Signature.ParamNames = append(m.Signature.ParamNames, paramNames[i])
+		if sig.Variadic() && i == params.Len()-1 {
+			m.Signature.ParamTypes = append(m.Signature.ParamTypes, "..."+types.TypeString(params.At(i).Type().(*types.Slice).Elem(), qualifier))
+		} else {
+			m.Signature.ParamTypes = append(m.Signature.ParamTypes, types.TypeString(params.At(i).Type(), qualifier))
+		}
+	}
+	if params.Len() > 0 && isContext(params.At(0).Type()) {
+		m.Signature.ContextIndex = 0
 	}
 
-	return typeNames
+	results := sig.Results()
+	for i := 0; i < results.Len(); i++ {
+		m.Signature.ResultTypes = append(m.Signature.ResultTypes, types.TypeString(results.At(i).Type(), qualifier))
+	}
+	if results.Len() > 0 && types.Identical(results.At(results.Len()-1).Type(), types.Universe.Lookup("error").Type()) {
+		m.Signature.ErrorIndex = results.Len() - 1
+	}
 }
 
-func typeName(expr ast.Expr) string {
-	switch t := expr.(type) {
-	case *ast.Ident:
-		return t.Name
-	case *ast.SelectorExpr:
-		return fmt.Sprintf("%s.%s", typeName(t.X), t.Sel.Name)
-	case *ast.StarExpr:
-		return "*" + typeName(t.X)
-	case *ast.ArrayType:
-		return "[]" + typeName(t.Elt)
-	case *ast.MapType:
-		return fmt.Sprintf("map[%s]%s", typeName(t.Key), typeName(t.Value))
-	case *ast.InterfaceType:
-		return "any"
-	case *ast.ChanType:
-		return "chan " + typeName(t.Value)
-	default:
-		panic(fmt.Sprintf("could not infer name for type %T", t))
-	}
+func isContext(t types.Type) bool {
+	named, ok := t.(*types.Named)
+	return ok && named.Obj().Pkg() != nil && named.Obj().Pkg().Path() == "context" && named.Obj().Name() == "Context"
 }
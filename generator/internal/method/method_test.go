@@ -1,8 +1,14 @@
 package method_test
 
 import (
+	"fmt"
 	"github.com/LeMikaelF/proxy-generator/generator/internal/method"
 	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"reflect"
 	"testing"
 )
 
@@ -10,20 +16,17 @@ func TestNew(t *testing.T) {
 	testCases := []struct {
 		name               string
 		passThroughMethods map[string]bool
-		funcDecl           *ast.FuncDecl
-		ident              *ast.Ident
-		hasStar            bool
+		src                string
+		methodName         string
 		expected           method.Method
 	}{
 		{
 			name:               "Simple function",
 			passThroughMethods: map[string]bool{},
-			funcDecl: &ast.FuncDecl{
-				Name: &ast.Ident{Name: "Foo"},
-				Type: &ast.FuncType{},
-			},
-			ident:   &ast.Ident{Name: "MyType"},
-			hasStar: false,
+			src: `package test
+type MyType struct{}
+func (m MyType) Foo() {}`,
+			methodName: "Foo",
 			expected: method.Method{
 				Name:                         "Foo",
 				Params:                       "",
@@ -32,64 +35,26 @@ func TestNew(t *testing.T) {
 				ParamNamesWithTypeAssertions: "",
 				Receiver:                     "MyType",
 				ResultTypes:                  nil,
-				ParamTypes:                   nil,
-				ResultExprs:                  nil,
 				Passthrough:                  false,
 			},
 		},
 		{
 			name:               "Function with parameters and results",
 			passThroughMethods: map[string]bool{},
-			funcDecl: &ast.FuncDecl{
-				Name: &ast.Ident{Name: "Bar"},
-				Type: &ast.FuncType{
-					Params: &ast.FieldList{
-						List: []*ast.Field{
-							{
-								Names: []*ast.Ident{{Name: "a"}},
-								Type:  &ast.Ident{Name: "int"},
-							},
-							{
-								Names: []*ast.Ident{{Name: "b"}},
-								Type:  &ast.Ident{Name: "string"},
-							},
-						},
-					},
-					Results: &ast.FieldList{
-						List: []*ast.Field{
-							{
-								Type: &ast.Ident{Name: "error"},
-							},
-						},
-					},
-				},
-			},
-			ident:   &ast.Ident{Name: "MyType"},
-			hasStar: true,
+			src: `package test
+type MyType struct{}
+func (m *MyType) Bar(a int, b string) error { return nil }`,
+			methodName: "Bar",
 			expected: method.Method{
 				Name:                         "Bar",
 				Params:                       "a int,b string",
 				Results:                      "error",
 				ParamNames:                   "a,b",
 				ParamNamesWithTypeAssertions: "args[0].(int),args[1].(string)",
+				ParamTypes:                   []string{"int", "string"},
 				Receiver:                     "*MyType",
 				ResultTypes:                  []string{"error"},
-				ParamTypes: []*ast.Field{
-					{
-						Names: []*ast.Ident{{Name: "a"}},
-						Type:  &ast.Ident{Name: "int"},
-					},
-					{
-						Names: []*ast.Ident{{Name: "b"}},
-						Type:  &ast.Ident{Name: "string"},
-					},
-				},
-				ResultExprs: []*ast.Field{
-					{
-						Type: &ast.Ident{Name: "error"},
-					},
-				},
-				Passthrough: false,
+				Passthrough:                  false,
 			},
 		},
 		{
@@ -97,12 +62,10 @@ func TestNew(t *testing.T) {
 			passThroughMethods: map[string]bool{
 				"Baz": true,
 			},
-			funcDecl: &ast.FuncDecl{
-				Name: &ast.Ident{Name: "Baz"},
-				Type: &ast.FuncType{},
-			},
-			ident:   &ast.Ident{Name: "MyType"},
-			hasStar: false,
+			src: `package test
+type MyType struct{}
+func (m MyType) Baz() {}`,
+			methodName: "Baz",
 			expected: method.Method{
 				Name:                         "Baz",
 				Params:                       "",
@@ -111,29 +74,405 @@ func TestNew(t *testing.T) {
 				ParamNamesWithTypeAssertions: "",
 				Receiver:                     "MyType",
 				ResultTypes:                  nil,
-				ParamTypes:                   nil,
-				ResultExprs:                  nil,
 				Passthrough:                  true,
 			},
 		},
+		{
+			name:               "Variadic function",
+			passThroughMethods: map[string]bool{},
+			src: `package test
+type MyType struct{}
+func (m *MyType) Logf(format string, values ...any) {}`,
+			methodName: "Logf",
+			expected: method.Method{
+				Name:                         "Logf",
+				Params:                       "format string,values ...any",
+				ParamNames:                   "format,values",
+				ParamNamesWithTypeAssertions: "args[0].(string),args[1].([]any)...",
+				ParamTypes:                   []string{"string", "[]any"},
+				Receiver:                     "*MyType",
+				Variadic:                     true,
+			},
+		},
+		{
+			name:               "Function-typed parameters and results",
+			passThroughMethods: map[string]bool{},
+			src: `package test
+import "context"
+type MyType struct{}
+func (m *MyType) Each(fn func(ctx context.Context, n int) error) func(...string) (bool, error) { return nil }`,
+			methodName: "Each",
+			expected: method.Method{
+				Name:                         "Each",
+				Params:                       "fn func(ctx context.Context, n int) error",
+				Results:                      "func(...string) (bool, error)",
+				ParamNames:                   "fn",
+				ParamNamesWithTypeAssertions: "args[0].(func(ctx context.Context, n int) error)",
+				ParamTypes:                   []string{"func(ctx context.Context, n int) error"},
+				Receiver:                     "*MyType",
+				ResultTypes:                  []string{"func(...string) (bool, error)"},
+			},
+		},
+		{
+			name:               "Generic receiver",
+			passThroughMethods: map[string]bool{},
+			src: `package test
+type MyType[T any, K comparable] struct{}
+func (m *MyType[V, _]) Values(filter map[string]V) []V { return nil }`,
+			methodName: "Values",
+			expected: method.Method{
+				Name:                         "Values",
+				Params:                       "filter map[string]V",
+				Results:                      "[]V",
+				ParamNames:                   "filter",
+				ParamNamesWithTypeAssertions: "args[0].(map[string]V)",
+				ParamTypes:                   []string{"map[string]V"},
+				Receiver:                     "*MyType[V, _]",
+				ReceiverTypeArgs:             "[V, _]",
+				ResultTypes:                  []string{"[]V"},
+			},
+		},
+		{
+			name:               "Complex type expressions",
+			passThroughMethods: map[string]bool{},
+			src: `package test
+import (
+	"encoding/xml"
+	"net/http"
+)
+type MyType struct{}
+func (m *MyType) Names(names map[string]*xml.Name, headers []chan<- http.Header) <-chan struct{} { return nil }`,
+			methodName: "Names",
+			expected: method.Method{
+				Name:                         "Names",
+				Params:                       "names map[string]*xml.Name,headers []chan<- http.Header",
+				Results:                      "<-chan struct{}",
+				ParamNames:                   "names,headers",
+				ParamNamesWithTypeAssertions: "args[0].(map[string]*xml.Name),args[1].([]chan<- http.Header)",
+				ParamTypes:                   []string{"map[string]*xml.Name", "[]chan<- http.Header"},
+				Receiver:                     "*MyType",
+				ResultTypes:                  []string{"<-chan struct{}"},
+			},
+		},
+		{
+			name:               "Unnamed and blank parameters",
+			passThroughMethods: map[string]bool{},
+			src: `package test
+import "context"
+type MyType struct{}
+func (m *MyType) Handle(context.Context, string) error { return nil }`,
+			methodName: "Handle",
+			expected: method.Method{
+				Name:                         "Handle",
+				Params:                       "p0 context.Context,p1 string",
+				Results:                      "error",
+				ParamNames:                   "p0,p1",
+				ParamNamesWithTypeAssertions: "args[0].(context.Context),args[1].(string)",
+				ParamTypes:                   []string{"context.Context", "string"},
+				Receiver:                     "*MyType",
+				ResultTypes:                  []string{"error"},
+			},
+		},
+		{
+			name:               "Blank parameters",
+			passThroughMethods: map[string]bool{},
+			src: `package test
+type MyType struct{}
+func (m *MyType) Blank(p1 int, _ string, _ ...bool) {}`,
+			methodName: "Blank",
+			expected: method.Method{
+				Name:                         "Blank",
+				Params:                       "p1 int,p1_ string,p2 ...bool",
+				ParamNames:                   "p1,p1_,p2",
+				ParamNamesWithTypeAssertions: "args[0].(int),args[1].(string),args[2].([]bool)...",
+				ParamTypes:                   []string{"int", "string", "[]bool"},
+				Receiver:                     "*MyType",
+				Variadic:                     true,
+			},
+		},
+		{
+			name:               "Collisions with generated identifiers",
+			passThroughMethods: map[string]bool{},
+			src: `package test
+import "encoding/xml"
+type MyType struct{}
+func (m *MyType) Run(args []string, method string, d int, xml xml.Name, result1 bool) (results int, err error) { return 0, nil }`,
+			methodName: "Run",
+			expected: method.Method{
+				Name:                         "Run",
+				Params:                       "args []string,method string,d int,xml_ xml.Name,result1 bool",
+				Results:                      "(int,error)",
+				ParamNames:                   "args,method,d,xml_,result1",
+				ParamNamesWithTypeAssertions: "args_[0].([]string),args_[1].(string),args_[2].(int),args_[3].(xml.Name),args_[4].(bool)",
+				ParamTypes:                   []string{"[]string", "string", "int", "xml.Name", "bool"},
+				Receiver:                     "*MyType",
+				ResultTypes:                  []string{"int", "error"},
+				Identifiers: method.Identifiers{
+					Receiver: "d_",
+					Args:     "args_",
+					Results:  "results_",
+					Result:   "result_",
+				},
+			},
+		},
+		{
+			name:               "Promoted from generic embedded struct",
+			passThroughMethods: map[string]bool{},
+			src: `package test
+import "time"
+type base[E any] struct{}
+func (b *base[E]) Health(timeout time.Duration, e E) error { return nil }
+type MyType[T any, K comparable] struct{ base[K] }`,
+			methodName: "Health",
+			expected: method.Method{
+				Name:                         "Health",
+				Params:                       "timeout time.Duration,e K",
+				Results:                      "error",
+				ParamNames:                   "timeout,e",
+				ParamNamesWithTypeAssertions: "args[0].(time.Duration),args[1].(K)",
+				ParamTypes:                   []string{"time.Duration", "K"},
+				Receiver:                     "*base[K]",
+				ReceiverTypeArgs:             "[T, K]",
+				ResultTypes:                  []string{"error"},
+			},
+		},
+		{
+			name:               "Interface method",
+			passThroughMethods: map[string]bool{},
+			src: `package test
+import "io"
+type MyType[T any] interface{ io.Reader; Get() T }`,
+			methodName: "Read",
+			expected: method.Method{
+				Name:                         "Read",
+				Params:                       "p []byte",
+				Results:                      "(int,error)",
+				ParamNames:                   "p",
+				ParamNamesWithTypeAssertions: "args[0].([]byte)",
+				ParamTypes:                   []string{"[]byte"},
+				Receiver:                     "MyType[T]",
+				ReceiverTypeArgs:             "[T]",
+				ResultTypes:                  []string{"int", "error"},
+			},
+		},
 	}
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			got := method.New(tc.passThroughMethods, tc.funcDecl, tc.ident, tc.hasStar)
-			if !equals(got, tc.expected) {
+			pkg, named, sel := lookupMethod(t, tc.src, "MyType", tc.methodName)
+			got := method.New(tc.passThroughMethods, sel, named, packageNameQualifier(pkg))
+			// The signature reported to invocation handlers is covered by TestNew_Signature.
+			got.Signature = method.Signature{}
+			if tc.expected.Identifiers == (method.Identifiers{}) {
+				tc.expected.Identifiers = defaultIdentifiers
+			}
+			if !reflect.DeepEqual(got, tc.expected) {
 				t.Errorf("Expected %+v, got %+v", tc.expected, got)
 			}
 		})
 	}
 }
 
-func equals(m method.Method, n method.Method) bool {
-	return m.Name == n.Name &&
-		m.Params == n.Params &&
-		m.Results == n.Results &&
-		m.ParamNames == n.ParamNames &&
-		m.ParamNamesWithTypeAssertions == n.ParamNamesWithTypeAssertions &&
-		m.Receiver == n.Receiver &&
-		m.Passthrough == n.Passthrough
+var defaultIdentifiers = method.Identifiers{Receiver: "d", Args: "args", Results: "results", Result: "result"}
+
+func packageNameQualifier(self *types.Package) types.Qualifier {
+	return func(pkg *types.Package) string {
+		if pkg == self {
+			return ""
+		}
+		return pkg.Name()
+	}
+}
+
+// lookupMethod type-checks src and selects methodName in the method set of a pointer to typeName, like the proxy does.
+func lookupMethod(t *testing.T, src string, typeName string, methodName string) (*types.Package, *types.Named, *types.Selection) {
+	t.Helper()
+
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "test.go", src, 0)
+	if err != nil {
+		t.Fatalf("Unexpected error parsing source: %v", err)
+	}
+
+	conf := types.Config{Importer: importer.ForCompiler(fset, "source", nil)}
+	pkg, err := conf.Check("test", fset, []*ast.File{f}, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error type-checking source: %v", err)
+	}
+
+	named := pkg.Scope().Lookup(typeName).Type().(*types.Named)
+	var methodSet *types.MethodSet
+	if types.IsInterface(named) {
+		methodSet = types.NewMethodSet(named)
+	} else {
+		methodSet = types.NewMethodSet(types.NewPointer(named))
+	}
+
+	sel := methodSet.Lookup(pkg, methodName)
+	if sel == nil {
+		t.Fatalf("Method %s not found", methodName)
+	}
+	return pkg, named, sel
+}
+
+func TestNew_Signature(t *testing.T) {
+	testCases := []struct {
+		name       string
+		src        string
+		methodName string
+		expected   method.Signature
+	}{
+		{
+			name:       "No parameters or results",
+			src:        `func (m *MyType) Foo() {}`,
+			methodName: "Foo",
+			expected:   method.Signature{ErrorIndex: -1, ContextIndex: -1},
+		},
+		{
+			name:       "Context and error",
+			src:        `func (m *MyType) Get(ctx context.Context, id string) (*MyType, error) { return nil, nil }`,
+			methodName: "Get",
+			expected: method.Signature{
+				ParamNames:   []string{"ctx", "id"},
+				ParamTypes:   []string{"context.Context", "string"},
+				ResultTypes:  []string{"*MyType", "error"},
+				ErrorIndex:   1,
+				ContextIndex: 0,
+			},
+		},
+		{
+			name:       "Context not leading and error not trailing",
+			src:        `func (m *MyType) Odd(id string, ctx context.Context) (error, int) { return nil, 0 }`,
+			methodName: "Odd",
+			expected: method.Signature{
+				ParamNames:   []string{"id", "ctx"},
+				ParamTypes:   []string{"string", "context.Context"},
+				ResultTypes:  []string{"error", "int"},
+				ErrorIndex:   -1,
+				ContextIndex: -1,
+			},
+		},
+		{
+			name:       "Unnamed variadic",
+			src:        `func (m *MyType) Log(string, ...any) {}`,
+			methodName: "Log",
+			expected: method.Signature{
+				ParamNames:   []string{"p0", "p1"},
+				ParamTypes:   []string{"string", "...any"},
+				ErrorIndex:   -1,
+				ContextIndex: -1,
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			src := "package test\nimport \"context\"\nvar _ context.Context\ntype MyType struct{}\n" + tc.src
+			pkg, named, sel := lookupMethod(t, src, "MyType", tc.methodName)
+			got := method.New(map[string]bool{}, sel, named, packageNameQualifier(pkg))
+			if !reflect.DeepEqual(got.Signature, tc.expected) {
+				t.Errorf("Expected %+v, got %+v", tc.expected, got.Signature)
+			}
+		})
+	}
+}
+
+func TestNew_GroupedParameters(t *testing.T) {
+	testCases := []struct {
+		name       string
+		src        string
+		methodName string
+		params     string
+		results    string
+		assertions string
+	}{
+		{
+			name:       "Grouped names",
+			src:        `func (m *MyType) Add(a, b int) int { return a + b }`,
+			methodName: "Add",
+			params:     "a int,b int",
+			results:    "int",
+			assertions: "args[0].(int),args[1].(int)",
+		},
+		{
+			name:       "Mixed groups",
+			src:        `func (m *MyType) Mixed(a, b int, c string, d, e []byte) {}`,
+			methodName: "Mixed",
+			params:     "a int,b int,c string,d []byte,e []byte",
+			results:    "",
+			assertions: "args[0].(int),args[1].(int),args[2].(string),args[3].([]byte),args[4].([]byte)",
+		},
+		{
+			name:       "Grouped names before variadic",
+			src:        `func (m *MyType) Sum(a, b int, rest ...int) int { return 0 }`,
+			methodName: "Sum",
+			params:     "a int,b int,rest ...int",
+			results:    "int",
+			assertions: "args[0].(int),args[1].(int),args[2].([]int)...",
+		},
+		{
+			name:       "Named result groups",
+			src:        `func (m *MyType) Div(a, b int) (q, r int, err error) { return 0, 0, nil }`,
+			methodName: "Div",
+			params:     "a int,b int",
+			results:    "(int,int,error)",
+			assertions: "args[0].(int),args[1].(int)",
+		},
+		{
+			name:       "Grouped names inside function types",
+			src:        `func (m *MyType) Bounds(f, g func(x, y int) (lo, hi int)) (lo, hi func(a, b int)) { return nil, nil }`,
+			methodName: "Bounds",
+			params:     "f func(x int, y int) (lo int, hi int),g func(x int, y int) (lo int, hi int)",
+			results:    "(func(a int, b int),func(a int, b int))",
+			assertions: "args[0].(func(x int, y int) (lo int, hi int)),args[1].(func(x int, y int) (lo int, hi int))",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			src := "package test\ntype MyType struct{}\n" + tc.src
+			pkg, named, sel := lookupMethod(t, src, "MyType", tc.methodName)
+			got := method.New(map[string]bool{}, sel, named, packageNameQualifier(pkg))
+
+			if got.Params != tc.params {
+				t.Errorf("Expected params '%s', got '%s'", tc.params, got.Params)
+			}
+			if got.Results != tc.results {
+				t.Errorf("Expected results '%s', got '%s'", tc.results, got.Results)
+			}
+			if got.ParamNamesWithTypeAssertions != tc.assertions {
+				t.Errorf("Expected type assertions '%s', got '%s'", tc.assertions, got.ParamNamesWithTypeAssertions)
+			}
+
+			compileProxyMethod(t, src, got)
+		})
+	}
+}
+
+// compileProxyMethod type-checks a method rendered from m the same way the proxy template does, next to src.
+func compileProxyMethod(t *testing.T, src string, m method.Method) {
+	t.Helper()
+
+	var ret string
+	if m.Results != "" {
+		ret = "return "
+	}
+	ids := m.Identifiers
+	proxySrc := fmt.Sprintf(`%s
+type proxy struct{ delegate *MyType }
+func (%s *proxy) %s(%s) %s {
+	%s := []any{%s}
+	%s%s.delegate.%s(%s)
+}`, src, ids.Receiver, m.Name, m.Params, m.Results, ids.Args, m.ParamNames, ret, ids.Receiver, m.Name, m.ParamNamesWithTypeAssertions)
+
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "proxy.go", proxySrc, 0)
+	if err != nil {
+		t.Fatalf("Generated method does not parse: %v\n%s", err, proxySrc)
+	}
+
+	conf := types.Config{Importer: importer.ForCompiler(fset, "source", nil)}
+	if _, err := conf.Check("test", fset, []*ast.File{f}, nil); err != nil {
+		t.Fatalf("Generated method does not compile: %v\n%s", err, proxySrc)
+	}
 }
@@ -0,0 +1,108 @@
+package source
+
+import (
+	"fmt"
+	"github.com/LeMikaelF/proxy-generator/generator/internal/diag"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strings"
+)
+
+const directivePrefix = "//proxy:"
+
+// directives control how a method is proxied, with comments such as //proxy:passthrough in its doc comment.
+type directives struct {
+	passthrough bool
+	skip        bool
+	tags        map[string]string
+}
+
+// docDirectives returns the directive comments of doc.
+func docDirectives(doc *ast.CommentGroup) []*ast.Comment {
+	if doc == nil {
+		return nil
+	}
+
+	var comments []*ast.Comment
+	for _, c := range doc.List {
+		if strings.HasPrefix(c.Text, directivePrefix) {
+			comments = append(comments, c)
+		}
+	}
+	return comments
+}
+
+// parseDirectives reads the directives of the declaration of fn, reporting those which are malformed, unknown or
+// conflicting.
+func (p *Package) parseDirectives(fn *types.Func) (directives, diag.List) {
+	var d directives
+	var diagnostics diag.List
+	// The positions of the directives which may conflict, reported on the second of them.
+	var passthroughPos, skipPos, tagPos token.Pos
+	for _, c := range p.declarations[fn.Pos()].directives {
+		fields := strings.Fields(strings.TrimPrefix(c.Text, directivePrefix))
+		if len(fields) == 0 {
+			diagnostics = append(diagnostics, p.diagnostic(c.Pos(), fn.Name(), "missing directive name after proxy:"))
+			continue
+		}
+
+		name, args := fields[0], fields[1:]
+		switch name {
+		case "passthrough", "skip":
+			if len(args) > 0 {
+				diagnostics = append(diagnostics, p.diagnostic(c.Pos(), fn.Name(), fmt.Sprintf("directive proxy:%s takes no arguments", name)))
+				continue
+			}
+			if name == "passthrough" {
+				d.passthrough = true
+				passthroughPos = c.Pos()
+			} else {
+				d.skip = true
+				skipPos = c.Pos()
+			}
+		case "tag":
+			if len(args) == 0 {
+				diagnostics = append(diagnostics, p.diagnostic(c.Pos(), fn.Name(), "directive proxy:tag expects key=value arguments"))
+				continue
+			}
+			if !tagPos.IsValid() {
+				tagPos = c.Pos()
+			}
+			for _, arg := range args {
+				key, value, ok := strings.Cut(arg, "=")
+				if !ok || key == "" {
+					diagnostics = append(diagnostics, p.diagnostic(c.Pos(), fn.Name(), fmt.Sprintf("malformed tag %q, expected key=value", arg)))
+					continue
+				}
+				if _, ok := d.tags[key]; ok {
+					diagnostics = append(diagnostics, p.diagnostic(c.Pos(), fn.Name(), fmt.Sprintf("duplicate tag %s", key)))
+					continue
+				}
+				if d.tags == nil {
+					d.tags = make(map[string]string)
+				}
+				d.tags[key] = value
+			}
+		default:
+			diagnostics = append(diagnostics, p.diagnostic(c.Pos(), fn.Name(), fmt.Sprintf("unknown directive proxy:%s, expected passthrough, skip or tag", name)))
+		}
+	}
+
+	if d.passthrough && d.skip {
+		diagnostics = append(diagnostics, p.diagnostic(later(passthroughPos, skipPos), fn.Name(), "conflicting directives proxy:passthrough and proxy:skip"))
+	}
+	if d.passthrough && tagPos.IsValid() {
+		diagnostics = append(diagnostics, p.diagnostic(later(passthroughPos, tagPos), fn.Name(),
+			"conflicting directives proxy:passthrough and proxy:tag, since passthrough methods aren't intercepted"))
+	}
+	return d, diagnostics
+}
+
+// later returns the later of two positions in the same file.
+func later(a, b token.Pos) token.Pos {
+	if a > b {
+		return a
+	}
+	return b
+}
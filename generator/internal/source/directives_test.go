@@ -0,0 +1,119 @@
+package source
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFindType_Directives(t *testing.T) {
+	pkg := loadPackage(t, `
+package test
+type TestStruct struct {}
+// Passthrough isn't intercepted.
+//
+//proxy:passthrough
+func (t *TestStruct) Passthrough() {}
+//proxy:skip
+func (t *TestStruct) Skipped() {}
+//proxy:tag cache=true audit=
+//proxy:tag role=admin
+func (t *TestStruct) Tagged() {}
+type TestInterface interface {
+	//proxy:skip
+	Skipped()
+	Kept()
+}
+`)
+
+	typ, err := pkg.FindType("TestStruct", Options{}, pkg.NewImports())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if expectedNames := []string{"Passthrough", "Tagged"}; !reflect.DeepEqual(methodNames(typ), expectedNames) {
+		t.Errorf("Expected methods %v, got %v", expectedNames, methodNames(typ))
+	}
+
+	if !typ.Methods[0].Passthrough || typ.Methods[1].Passthrough {
+		t.Errorf("Expected only Passthrough to pass through")
+	}
+
+	if typ.Methods[0].Doc != "Passthrough isn't intercepted.\n" {
+		t.Errorf("Expected directives to be left out of the doc comment, got '%s'", typ.Methods[0].Doc)
+	}
+
+	expectedTags := map[string]string{"cache": "true", "audit": "", "role": "admin"}
+	if !reflect.DeepEqual(typ.Methods[1].Tags, expectedTags) {
+		t.Errorf("Expected tags %v, got %v", expectedTags, typ.Methods[1].Tags)
+	}
+
+	typ, err = pkg.FindType("TestInterface", Options{}, pkg.NewImports())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if expectedNames := []string{"Kept"}; !reflect.DeepEqual(methodNames(typ), expectedNames) {
+		t.Errorf("Expected methods %v, got %v", expectedNames, methodNames(typ))
+	}
+}
+
+func TestFindType_InvalidDirectives(t *testing.T) {
+	pkg := loadPackage(t, `
+package test
+type TestStruct struct {}
+//proxy:passthrough now
+func (t *TestStruct) Arguments() {}
+//proxy:cache
+func (t *TestStruct) Unknown() {}
+//proxy:tag cache
+//proxy:tag =true role=admin role=user
+func (t *TestStruct) Tags() {}
+//proxy:
+func (t *TestStruct) Empty() {}
+//proxy:passthrough
+//proxy:skip
+func (t *TestStruct) PassthroughSkipped() {}
+//proxy:tag cache=true
+//proxy:passthrough
+func (t *TestStruct) PassthroughTagged() {}
+`)
+
+	_, err := pkg.FindType("TestStruct", Options{}, pkg.NewImports())
+
+	expected := []string{
+		"method Arguments: directive proxy:passthrough takes no arguments",
+		"method Unknown: unknown directive proxy:cache, expected passthrough, skip or tag",
+		`method Tags: malformed tag "cache", expected key=value`,
+		`method Tags: malformed tag "=true", expected key=value`,
+		"method Tags: duplicate tag role",
+		"method Empty: missing directive name after proxy:",
+		"method PassthroughSkipped: conflicting directives proxy:passthrough and proxy:skip",
+		"method PassthroughTagged: conflicting directives proxy:passthrough and proxy:tag, since passthrough methods aren't intercepted",
+	}
+	if err == nil || err.Error() != strings.Join(expected, "\n") {
+		t.Errorf("Expected error '%s', got '%v'", strings.Join(expected, "\n"), err)
+	}
+}
+
+func TestFindType_SkippedDelegateKind(t *testing.T) {
+	pkg := loadPackage(t, `
+package test
+type TestStruct struct {}
+func (t TestStruct) Value() {}
+//proxy:skip
+func (t *TestStruct) Pointer() {}
+`)
+
+	typ, err := pkg.FindType("TestStruct", Options{DelegateKind: DelegateAuto}, pkg.NewImports())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if !typ.ValueDelegate {
+		t.Errorf("Expected a skipped pointer method not to prevent a value delegate")
+	}
+	if expectedNames := []string{"Value"}; !reflect.DeepEqual(methodNames(typ), expectedNames) {
+		t.Errorf("Expected methods %v, got %v", expectedNames, methodNames(typ))
+	}
+}
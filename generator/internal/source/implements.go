@@ -0,0 +1,87 @@
+package source
+
+import (
+	"fmt"
+	"github.com/LeMikaelF/proxy-generator/generator/internal/diag"
+	"go/types"
+	"strings"
+)
+
+// CheckImplements verifies that a proxy of t satisfies the interface named name, which is either declared in the
+// package, or qualified by the path of the package declaring it, as in io.Reader or example.com/pkg.Interface. It
+// returns the interface as referenced from the generated code, adding its package to imports.
+func (p *Package) CheckImplements(t *Type, name string, imports *Imports) (string, error) {
+	named, err := p.lookupInterface(name)
+	if err != nil {
+		return "", err
+	}
+
+	if t.TypeParams != "" {
+		return "", fmt.Errorf("cannot check that generic type %s implements %s", t.Name, name)
+	}
+	if named.TypeParams().Len() > 0 {
+		return "", fmt.Errorf("cannot check implementation of generic interface %s", name)
+	}
+
+	iface := named.Underlying().(*types.Interface)
+	var diagnostics diag.List
+	for i := 0; i < iface.NumMethods(); i++ {
+		fn := iface.Method(i)
+		expected := fn.Type().(*types.Signature)
+
+		sel, ok := t.selections[fn.Name()]
+		// Unexported methods of interfaces from other packages can only be implemented in those packages.
+		if !ok || !fn.Exported() && fn.Pkg().Path() != imports.self.Path() {
+			diagnostics = append(diagnostics, p.diagnostic(fn.Pos(), fn.Name(), fmt.Sprintf("%sProxy does not implement %s: missing method %s%s",
+				t.Name, name, fn.Name(), p.signatureString(expected))))
+			continue
+		}
+
+		if actual := sel.Type().(*types.Signature); !types.Identical(actual, expected) {
+			diagnostics = append(diagnostics, p.diagnostic(sel.Obj().Pos(), fn.Name(), fmt.Sprintf("%sProxy does not implement %s: has signature %s, expected %s",
+				t.Name, name, p.signatureString(actual), p.signatureString(expected))))
+		}
+	}
+
+	if err := diagnostics.Err(); err != nil {
+		return "", err
+	}
+	return types.TypeString(named, imports.Qualifier), nil
+}
+
+// lookupInterface resolves name to an interface, importing the package that declares it if needed.
+func (p *Package) lookupInterface(name string) (*types.Named, error) {
+	pkg := p.pkg
+	typeName := name
+	if i := strings.LastIndex(name, "."); i >= 0 {
+		typeName = name[i+1:]
+		if path := name[:i]; path != p.pkg.Path() && path != p.pkg.Name() {
+			imported, err := p.importer.Import(path)
+			if err != nil {
+				return nil, fmt.Errorf("error importing package of interface %s: %v", name, err)
+			}
+			pkg = imported
+		}
+	}
+
+	obj, ok := pkg.Scope().Lookup(typeName).(*types.TypeName)
+	if !ok {
+		return nil, fmt.Errorf("could not find interface %s", name)
+	}
+
+	named, ok := obj.Type().(*types.Named)
+	if !ok || !types.IsInterface(named) {
+		return nil, fmt.Errorf("%s is not an interface", name)
+	}
+	return named, nil
+}
+
+// signatureString renders sig without its func keyword, qualifying types from other packages by package name.
+func (p *Package) signatureString(sig *types.Signature) string {
+	return strings.TrimPrefix(types.TypeString(sig, func(pkg *types.Package) string {
+		if pkg == p.pkg {
+			return ""
+		}
+		return pkg.Name()
+	}), "func")
+}
@@ -0,0 +1,54 @@
+package source
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCheckImplements(t *testing.T) {
+	pkg := loadPackage(t, `
+package test
+type Getter interface {
+	Get(key string) (string, error)
+}
+type TestStruct struct {}
+func (t *TestStruct) Get(key string) (string, error) { return "", nil }
+func (t *TestStruct) Read(p []byte) int { return 0 }
+`)
+
+	testCases := []struct {
+		iface         string
+		expected      string
+		expectedError string
+	}{
+		{"Getter", "Getter", ""},
+		{"test.Getter", "Getter", ""},
+		{"fmt.Stringer", "", "method String: TestStructProxy does not implement fmt.Stringer: missing method String() string"},
+		{"io.Reader", "", "method Read: TestStructProxy does not implement io.Reader: has signature (p []byte) int, expected (p []byte) (n int, err error)"},
+		{"TestStruct", "", "TestStruct is not an interface"},
+		{"io.Missing", "", "could not find interface io.Missing"},
+	}
+
+	for _, tc := range testCases {
+		typ, err := pkg.FindType("TestStruct", Options{}, pkg.NewImports())
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		got, err := pkg.CheckImplements(typ, tc.iface, pkg.NewImports())
+		if tc.expectedError != "" {
+			// Diagnostics are prefixed with the position of the method, which depends on the environment for imported
+			// interfaces.
+			if err == nil || !strings.HasSuffix(err.Error(), tc.expectedError) {
+				t.Errorf("Expected error '%s' for %s, got '%v'", tc.expectedError, tc.iface, err)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("Expected no error for %s, got %v", tc.iface, err)
+		} else if got != tc.expected {
+			t.Errorf("Expected %s to be referenced as %s, got %s", tc.iface, tc.expected, got)
+		}
+	}
+}
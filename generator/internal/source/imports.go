@@ -0,0 +1,59 @@
+package source
+
+import (
+	"fmt"
+	"go/types"
+	"sort"
+	"strconv"
+)
+
+// Imports tracks the packages referenced by generated code, and the name under which each one is imported.
+type Imports struct {
+	self  *types.Package
+	names map[string]string // import path to name
+	paths map[string]string // name to import path
+}
+
+func newImports(self *types.Package) *Imports {
+	return &Imports{
+		self:  self,
+		names: make(map[string]string),
+		paths: make(map[string]string),
+	}
+}
+
+// Qualifier is a types.Qualifier recording every package it qualifies. Packages sharing a name are disambiguated with
+// a numeric suffix.
+func (i *Imports) Qualifier(pkg *types.Package) string {
+	if pkg.Path() == i.self.Path() {
+		return ""
+	}
+
+	if name, ok := i.names[pkg.Path()]; ok {
+		return name
+	}
+
+	name := pkg.Name()
+	for n := 1; i.paths[name] != ""; n++ {
+		name = pkg.Name() + strconv.Itoa(n)
+	}
+
+	i.names[pkg.Path()] = name
+	i.paths[name] = pkg.Path()
+	return name
+}
+
+// Specs returns the import specs, sorted by import path.
+func (i *Imports) Specs() []string {
+	paths := make([]string, 0, len(i.names))
+	for path := range i.names {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	specs := make([]string, 0, len(paths))
+	for _, path := range paths {
+		specs = append(specs, fmt.Sprintf("%s %q", i.names[path], path))
+	}
+	return specs
+}
@@ -0,0 +1,34 @@
+package source
+
+import (
+	"go/types"
+	"reflect"
+	"testing"
+)
+
+func TestImports(t *testing.T) {
+	self := types.NewPackage("example.com/test", "test")
+	imports := newImports(self)
+
+	testCases := []struct {
+		pkg      *types.Package
+		expected string
+	}{
+		{self, ""},
+		{types.NewPackage("math/rand", "rand"), "rand"},
+		{types.NewPackage("crypto/rand", "rand"), "rand1"},
+		{types.NewPackage("math/rand", "rand"), "rand"},
+		{types.NewPackage("context", "context"), "context"},
+	}
+
+	for _, tc := range testCases {
+		if got := imports.Qualifier(tc.pkg); got != tc.expected {
+			t.Errorf("Expected %s to be qualified as '%s', got '%s'", tc.pkg.Path(), tc.expected, got)
+		}
+	}
+
+	expectedSpecs := []string{`context "context"`, `rand1 "crypto/rand"`, `rand "math/rand"`}
+	if !reflect.DeepEqual(imports.Specs(), expectedSpecs) {
+		t.Errorf("Expected specs %v, got %v", expectedSpecs, imports.Specs())
+	}
+}
@@ -0,0 +1,499 @@
+package source
+
+import (
+	"errors"
+	"fmt"
+	"github.com/LeMikaelF/proxy-generator/generator/internal/diag"
+	"github.com/LeMikaelF/proxy-generator/generator/internal/method"
+	"go/ast"
+	"go/token"
+	"go/types"
+	pathpkg "path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Package is a type-checked package containing the type to proxy.
+type Package struct {
+	pkg          *types.Package
+	fset         *token.FileSet
+	importer     types.Importer
+	typeErrors   []error
+	declarations map[token.Pos]declaration // method declarations, by the position of their name
+	generated    []*ast.File
+}
+
+// declaration is the source of a method declaration.
+type declaration struct {
+	doc        string
+	directives []*ast.Comment
+	signature  *ast.FuncType
+}
+
+// Load type-checks files as the package with import path path, resolving their imports with importer. Type errors are
+// recorded rather than returned, so that a package can still be proxied while unrelated code (such as a stale
+// generated file) doesn't compile. They are reported only if they affect the proxied type.
+func Load(path string, fset *token.FileSet, files []*ast.File, importer types.Importer) (*Package, error) {
+	if len(files) == 0 {
+		return nil, errors.New("no files to load")
+	}
+
+	p := &Package{fset: fset, importer: importer, declarations: methodDeclarations(files)}
+	for _, file := range files {
+		if isGenerated(file) {
+			p.generated = append(p.generated, file)
+		}
+	}
+	conf := types.Config{
+		Importer: importer,
+		Error: func(err error) {
+			p.typeErrors = append(p.typeErrors, err)
+		},
+	}
+
+	pkg, _ := conf.Check(path, fset, files, nil)
+	if pkg == nil {
+		return nil, fmt.Errorf("error type-checking package %s", files[0].Name.Name)
+	}
+	p.pkg = pkg
+
+	return p, nil
+}
+
+// methodDeclarations collects the methods declared in files, including interface methods.
+func methodDeclarations(files []*ast.File) map[token.Pos]declaration {
+	declarations := make(map[token.Pos]declaration)
+	for _, file := range files {
+		ast.Inspect(file, func(node ast.Node) bool {
+			switch node := node.(type) {
+			case *ast.FuncDecl:
+				if node.Recv != nil {
+					declarations[node.Name.Pos()] = declaration{
+						doc:        node.Doc.Text(),
+						directives: docDirectives(node.Doc),
+						signature:  node.Type,
+					}
+				}
+			case *ast.InterfaceType:
+				for _, field := range node.Methods.List {
+					if signature, ok := field.Type.(*ast.FuncType); ok && len(field.Names) > 0 {
+						declarations[field.Names[0].Pos()] = declaration{
+							doc:        field.Doc.Text(),
+							directives: docDirectives(field.Doc),
+							signature:  signature,
+						}
+					}
+				}
+			}
+			return true
+		})
+	}
+	return declarations
+}
+
+var generatedComment = regexp.MustCompile(`^// Code generated .* DO NOT EDIT\.$`)
+
+// isGenerated reports whether file is marked as generated, as proxies are, by a comment anywhere in the file.
+func isGenerated(file *ast.File) bool {
+	for _, group := range file.Comments {
+		for _, c := range group.List {
+			if generatedComment.MatchString(c.Text) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (p *Package) Name() string {
+	return p.pkg.Name()
+}
+
+// NewImports returns an empty import set for code generated in this package.
+func (p *Package) NewImports() *Imports {
+	return newImports(p.pkg)
+}
+
+// NewExternalImports returns an empty import set for code generated in the package with import path path, which
+// imports this package to refer to its declarations.
+func (p *Package) NewExternalImports(path string) *Imports {
+	return newImports(types.NewPackage(path, pathpkg.Base(path)))
+}
+
+// Type describes a type to proxy.
+type Type struct {
+	Name          string
+	Reference     string // the name qualified by its package, when generated in another package
+	IsInterface   bool
+	ValueDelegate bool   // whether the proxy holds its delegate by value rather than by pointer
+	TypeParams    string // e.g. [T any, K comparable]
+	TypeArgs      string // e.g. [T, K]
+	Methods       []method.Method
+	// selections holds the selection of each proxied method, by name, for checking interface conformance.
+	selections map[string]*types.Selection
+}
+
+// HasMethod reports whether the method named name is proxied.
+func (t *Type) HasMethod(name string) bool {
+	_, ok := t.selections[name]
+	return ok
+}
+
+// DelegateKind decides whether a proxy holds its delegate by value or by pointer. It doesn't apply to interfaces.
+type DelegateKind string
+
+const (
+	DelegatePointer DelegateKind = "pointer"
+	DelegateValue   DelegateKind = "value"
+	// DelegateAuto holds the delegate by value when all its proxied methods have value receivers.
+	DelegateAuto DelegateKind = "auto"
+)
+
+// Options controls which methods of a type are proxied, and how.
+type Options struct {
+	PassthroughMethods map[string]bool
+	IncludeUnexported  bool
+	DelegateKind       DelegateKind
+	// Tags holds tags of methods by method name, to which the tags of their //proxy:tag directives are added.
+	Tags map[string]map[string]string
+}
+
+func (o Options) includes(fn *types.Func) bool {
+	return fn.Exported() || o.IncludeUnexported
+}
+
+// TypeNames expands names into the names of the types to proxy. A name containing wildcards, as understood by
+// path.Match, matches every type with methods declared in the package outside generated files, other than aliases, in
+// alphabetical order. Other names are kept as is.
+func (p *Package) TypeNames(names []string) ([]string, error) {
+	var typeNames []string
+	seen := make(map[string]bool)
+	add := func(name string) {
+		if !seen[name] {
+			seen[name] = true
+			typeNames = append(typeNames, name)
+		}
+	}
+
+	for _, name := range names {
+		if !strings.ContainsAny(name, "*?[") {
+			add(name)
+			continue
+		}
+
+		matched := false
+		for _, candidate := range p.pkg.Scope().Names() {
+			ok, err := pathpkg.Match(name, candidate)
+			if err != nil {
+				return nil, fmt.Errorf("invalid type pattern %s: %v", name, err)
+			}
+			if obj, isType := p.pkg.Scope().Lookup(candidate).(*types.TypeName); ok && isType && p.proxiable(obj) {
+				matched = true
+				add(candidate)
+			}
+		}
+		if !matched {
+			return nil, fmt.Errorf("no type matches pattern %s", name)
+		}
+	}
+
+	return typeNames, nil
+}
+
+// Declaration returns the file declaring name in the package scope, if any.
+func (p *Package) Declaration(name string) (string, bool) {
+	obj := p.pkg.Scope().Lookup(name)
+	if obj == nil {
+		return "", false
+	}
+	return p.fset.Position(obj.Pos()).Filename, true
+}
+
+// proxiable reports whether a wildcard may match obj, which can't be proxied if it's an alias or has no methods.
+func (p *Package) proxiable(obj *types.TypeName) bool {
+	if obj.IsAlias() || p.inGeneratedFile(obj) {
+		return false
+	}
+	// Interfaces have no methods through a pointer, unlike structs.
+	typ := obj.Type()
+	if !types.IsInterface(typ) {
+		typ = types.NewPointer(typ)
+	}
+	return types.NewMethodSet(typ).Len() > 0
+}
+
+func (p *Package) inGeneratedFile(obj types.Object) bool {
+	for _, file := range p.generated {
+		if file.Pos() <= obj.Pos() && obj.Pos() < file.End() {
+			return true
+		}
+	}
+	return false
+}
+
+// FindType looks up the type named typeName and describes its methods. Every package referenced by the description is
+// added to imports.
+func (p *Package) FindType(typeName string, options Options, imports *Imports) (*Type, error) {
+	obj, ok := p.pkg.Scope().Lookup(typeName).(*types.TypeName)
+	if !ok {
+		return nil, fmt.Errorf("could not find type declaration with name %s", typeName)
+	}
+
+	named, ok := obj.Type().(*types.Named)
+	if !ok {
+		return nil, fmt.Errorf("type %s is not a named type", typeName)
+	}
+
+	external := imports.self.Path() != p.pkg.Path()
+	if external && !obj.Exported() {
+		return nil, diag.List{p.diagnostic(obj.Pos(), "", fmt.Sprintf("cannot proxy unexported type %s from another package", typeName))}
+	}
+
+	t := &Type{Name: typeName, Reference: typeName, selections: make(map[string]*types.Selection)}
+	if external {
+		t.Reference = imports.Qualifier(p.pkg) + "." + typeName
+	}
+	populateTypeParams(t, named, imports)
+
+	var selections []*types.Selection
+	if _, ok := named.Underlying().(*types.Interface); ok {
+		t.IsInterface = true
+		methodSet := types.NewMethodSet(named)
+		for i := 0; i < methodSet.Len(); i++ {
+			selections = append(selections, methodSet.At(i))
+		}
+		selections = p.filter(selections, options, imports)
+	} else {
+		// A proxy holding a pointer to its delegate can call methods with pointer receivers, including those promoted
+		// from embedded fields, while one holding a value is limited to the value's method set.
+		pointerSelections := p.filter(structSelections(named, types.NewMethodSet(types.NewPointer(named))), options, imports)
+		valueSelections := p.filter(structSelections(named, types.NewMethodSet(named)), options, imports)
+
+		switch options.DelegateKind {
+		case DelegateValue:
+			// Leaving methods out is only done silently when deciding the delegate kind automatically.
+			if len(valueSelections) < len(pointerSelections) {
+				return nil, p.pointerReceiverDiagnostics(pointerSelections, valueSelections)
+			}
+			t.ValueDelegate = true
+		case DelegateAuto:
+			t.ValueDelegate = len(valueSelections) == len(pointerSelections)
+		}
+
+		selections = pointerSelections
+		if t.ValueDelegate {
+			selections = valueSelections
+		}
+	}
+
+	// Every method is checked before failing, so that all problems are reported together.
+	var diagnostics diag.List
+	for _, sel := range selections {
+		fn := sel.Obj().(*types.Func)
+		directives, directiveDiagnostics := p.parseDirectives(fn)
+		if len(directiveDiagnostics) > 0 {
+			diagnostics = append(diagnostics, directiveDiagnostics...)
+			continue
+		}
+		if signatureDiagnostics := p.checkSignature(fn); len(signatureDiagnostics) > 0 {
+			diagnostics = append(diagnostics, signatureDiagnostics...)
+			continue
+		}
+		if external {
+			if name := p.unexportedReference(sel.Type()); name != "" {
+				diagnostics = append(diagnostics, p.diagnostic(fn.Pos(), fn.Name(),
+					fmt.Sprintf("refers to unexported type %s, which can't be referenced from another package", name)))
+				continue
+			}
+		}
+		m := method.New(options.PassthroughMethods, sel, named, imports.Qualifier)
+		m.Doc = p.declarations[fn.Pos()].doc
+		m.Position = p.position(fn.Pos())
+		m.Passthrough = m.Passthrough || directives.passthrough
+		m.Tags = mergeTags(options.Tags[fn.Name()], directives.tags)
+		t.Methods = append(t.Methods, m)
+		t.selections[fn.Name()] = sel
+	}
+
+	if err := diagnostics.Err(); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// pointerReceiverDiagnostics reports the methods of pointerSelections missing from valueSelections, which have pointer
+// receivers.
+func (p *Package) pointerReceiverDiagnostics(pointerSelections []*types.Selection, valueSelections []*types.Selection) diag.List {
+	values := make(map[string]bool, len(valueSelections))
+	for _, sel := range valueSelections {
+		values[sel.Obj().Name()] = true
+	}
+
+	var diagnostics diag.List
+	for _, sel := range pointerSelections {
+		if fn := sel.Obj(); !values[fn.Name()] {
+			diagnostics = append(diagnostics, p.diagnostic(fn.Pos(), fn.Name(), "has a pointer receiver, which a proxy holding its delegate by value can't call"))
+		}
+	}
+	return diagnostics
+}
+
+// mergeTags returns the union of configured and directive tags, with directives taking precedence as they're closer to
+// the code.
+func mergeTags(configured map[string]string, directive map[string]string) map[string]string {
+	if len(configured) == 0 {
+		return directive
+	}
+
+	tags := make(map[string]string, len(configured)+len(directive))
+	for key, value := range configured {
+		tags[key] = value
+	}
+	for key, value := range directive {
+		tags[key] = value
+	}
+	return tags
+}
+
+// position returns the file:line of pos, naming the file without its directory so that the generated code doesn't
+// depend on where the module is checked out.
+func (p *Package) position(pos token.Pos) string {
+	if !pos.IsValid() {
+		return ""
+	}
+	position := p.fset.Position(pos)
+	return fmt.Sprintf("%s:%d", filepath.Base(position.Filename), position.Line)
+}
+
+func (p *Package) diagnostic(pos token.Pos, method string, message string) diag.Diagnostic {
+	return diag.New(p.fset.Position(pos), method, message)
+}
+
+// filter removes the methods that shouldn't, or can't, be proxied from the package of imports, including those marked
+// with //proxy:skip.
+func (p *Package) filter(selections []*types.Selection, options Options, imports *Imports) []*types.Selection {
+	var filtered []*types.Selection
+	for _, sel := range selections {
+		fn := sel.Obj().(*types.Func)
+		// Unexported methods from other packages, such as those promoted from embedded types, can't be called from the
+		// generated code.
+		if !options.includes(fn) || !fn.Exported() && fn.Pkg().Path() != imports.self.Path() {
+			continue
+		}
+		// Skipped methods are left out here, so that they don't decide the delegate kind. Those with invalid directives
+		// are kept, to be reported.
+		if directives, diagnostics := p.parseDirectives(fn); directives.skip && len(diagnostics) == 0 {
+			continue
+		}
+		filtered = append(filtered, sel)
+	}
+	return filtered
+}
+
+// structSelections orders the method set of a struct with declared methods first, in declaration order, followed by
+// promoted methods.
+func structSelections(named *types.Named, methodSet *types.MethodSet) []*types.Selection {
+	var selections []*types.Selection
+	for i := 0; i < named.NumMethods(); i++ {
+		fn := named.Method(i)
+		if sel := methodSet.Lookup(fn.Pkg(), fn.Name()); sel != nil {
+			selections = append(selections, sel)
+		}
+	}
+
+	for i := 0; i < methodSet.Len(); i++ {
+		if sel := methodSet.At(i); len(sel.Index()) > 1 {
+			selections = append(selections, sel)
+		}
+	}
+
+	return selections
+}
+
+func populateTypeParams(t *Type, named *types.Named, imports *Imports) {
+	typeParams := named.TypeParams()
+	if typeParams.Len() == 0 {
+		return
+	}
+
+	declarations := make([]string, 0, typeParams.Len())
+	names := make([]string, 0, typeParams.Len())
+	for i := 0; i < typeParams.Len(); i++ {
+		typeParam := typeParams.At(i)
+		declarations = append(declarations, typeParam.Obj().Name()+" "+types.TypeString(typeParam.Constraint(), imports.Qualifier))
+		names = append(names, typeParam.Obj().Name())
+	}
+
+	t.TypeParams = "[" + strings.Join(declarations, ", ") + "]"
+	t.TypeArgs = "[" + strings.Join(names, ", ") + "]"
+}
+
+// unexportedReference returns the name of an unexported type of the package referenced by typ, if any.
+func (p *Package) unexportedReference(typ types.Type) string {
+	switch typ := typ.(type) {
+	case *types.Named:
+		if obj := typ.Obj(); obj.Pkg() == p.pkg && !obj.Exported() {
+			return obj.Name()
+		}
+		for i := 0; i < typ.TypeArgs().Len(); i++ {
+			if name := p.unexportedReference(typ.TypeArgs().At(i)); name != "" {
+				return name
+			}
+		}
+	case *types.Pointer:
+		return p.unexportedReference(typ.Elem())
+	case *types.Slice:
+		return p.unexportedReference(typ.Elem())
+	case *types.Array:
+		return p.unexportedReference(typ.Elem())
+	case *types.Chan:
+		return p.unexportedReference(typ.Elem())
+	case *types.Map:
+		if name := p.unexportedReference(typ.Key()); name != "" {
+			return name
+		}
+		return p.unexportedReference(typ.Elem())
+	case *types.Signature:
+		for _, tuple := range []*types.Tuple{typ.Params(), typ.Results()} {
+			for i := 0; i < tuple.Len(); i++ {
+				if name := p.unexportedReference(tuple.At(i).Type()); name != "" {
+					return name
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// checkSignature reports the package's type errors if they prevented resolving the types in fn's signature. Only the
+// errors located in the signature are reported, unless it has none, as for methods promoted from an invalid type.
+func (p *Package) checkSignature(fn *types.Func) diag.List {
+	if !strings.Contains(types.TypeString(fn.Type(), nil), "invalid type") {
+		return nil
+	}
+
+	var inSignature, all diag.List
+	signature := p.declarations[fn.Pos()].signature
+	for _, err := range p.typeErrors {
+		typeErr, ok := err.(types.Error)
+		if !ok {
+			all = append(all, p.diagnostic(fn.Pos(), fn.Name(), err.Error()))
+			continue
+		}
+
+		d := diag.New(typeErr.Fset.Position(typeErr.Pos), fn.Name(), typeErr.Msg)
+		all = append(all, d)
+		if signature != nil && signature.Pos() <= typeErr.Pos && typeErr.Pos < signature.End() {
+			inSignature = append(inSignature, d)
+		}
+	}
+
+	if len(inSignature) > 0 {
+		return inSignature
+	}
+	if len(all) == 0 {
+		return diag.List{p.diagnostic(fn.Pos(), fn.Name(), "could not resolve the signature")}
+	}
+	return all
+}
@@ -0,0 +1,455 @@
+package source
+
+import (
+	"github.com/LeMikaelF/proxy-generator/generator/internal/diag"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func loadPackage(t *testing.T, srcs ...string) *Package {
+	t.Helper()
+
+	fset := token.NewFileSet()
+	var files []*ast.File
+	for _, src := range srcs {
+		f, err := parser.ParseFile(fset, "", src, parser.ParseComments)
+		if err != nil {
+			t.Fatalf("Unexpected error parsing source: %v", err)
+		}
+		files = append(files, f)
+	}
+
+	pkg, err := Load("example.com/test", fset, files, importer.ForCompiler(fset, "source", nil))
+	if err != nil {
+		t.Fatalf("Unexpected error loading package: %v", err)
+	}
+	return pkg
+}
+
+func methodNames(typ *Type) []string {
+	var names []string
+	for _, m := range typ.Methods {
+		names = append(names, m.Name)
+	}
+	return names
+}
+
+func TestFindType_NotFound(t *testing.T) {
+	pkg := loadPackage(t, `
+package test
+type TestStruct struct {}
+`)
+
+	_, err := pkg.FindType("Missing", Options{}, pkg.NewImports())
+
+	expectedErrMsg := "could not find type declaration with name Missing"
+	if err == nil || err.Error() != expectedErrMsg {
+		t.Errorf("Expected error '%s', got '%v'", expectedErrMsg, err)
+	}
+}
+
+func TestFindType_Methods(t *testing.T) {
+	pkg := loadPackage(t, `
+package test
+import (
+	"context"
+	"encoding/xml"
+)
+type TestStruct struct {}
+func (t TestStruct) TestMethod() {}
+func (t *TestStruct) Each(ctx context.Context, fn func(xml.Name) error) map[string]*xml.Name { return nil }
+`, `
+package test
+func (t *TestStruct) OtherFile() {}
+type Other struct {}
+func (o Other) NotProxied() {}
+`)
+
+	imports := pkg.NewImports()
+	typ, err := pkg.FindType("TestStruct", Options{}, imports)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if typ.IsInterface {
+		t.Error("Expected a struct, got an interface")
+	}
+
+	expectedNames := []string{"TestMethod", "Each", "OtherFile"}
+	if !reflect.DeepEqual(methodNames(typ), expectedNames) {
+		t.Errorf("Expected methods %v, got %v", expectedNames, methodNames(typ))
+	}
+
+	if typ.Methods[0].Receiver != "TestStruct" || typ.Methods[1].Receiver != "*TestStruct" {
+		t.Errorf("Unexpected receivers %s and %s", typ.Methods[0].Receiver, typ.Methods[1].Receiver)
+	}
+
+	expectedImports := []string{`context "context"`, `xml "encoding/xml"`}
+	if !reflect.DeepEqual(imports.Specs(), expectedImports) {
+		t.Errorf("Expected imports %v, got %v", expectedImports, imports.Specs())
+	}
+}
+
+func TestFindType_Generic(t *testing.T) {
+	pkg := loadPackage(t, `
+package test
+import "fmt"
+type TestStruct[T, U any, K fmt.Stringer] struct {}
+func (t *TestStruct[T, U, K]) Get(key K) T { var v T; return v }
+`)
+
+	imports := pkg.NewImports()
+	typ, err := pkg.FindType("TestStruct", Options{}, imports)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if typ.TypeParams != "[T any, U any, K fmt.Stringer]" || typ.TypeArgs != "[T, U, K]" {
+		t.Errorf("Unexpected type parameters %s %s", typ.TypeParams, typ.TypeArgs)
+	}
+
+	if typ.Methods[0].Receiver != "*TestStruct[T, U, K]" {
+		t.Errorf("Unexpected receiver %s", typ.Methods[0].Receiver)
+	}
+
+	expectedImports := []string{`fmt "fmt"`}
+	if !reflect.DeepEqual(imports.Specs(), expectedImports) {
+		t.Errorf("Expected imports %v, got %v", expectedImports, imports.Specs())
+	}
+}
+
+func TestFindType_Interface(t *testing.T) {
+	pkg := loadPackage(t, `
+package test
+import "io"
+type Store interface {
+	io.Closer
+	Getter
+	Get(key string) string
+}
+`, `
+package test
+type Getter interface {
+	Get(key string) string
+	GetAll(keys ...string) []string
+}
+`)
+
+	typ, err := pkg.FindType("Store", Options{}, pkg.NewImports())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if !typ.IsInterface {
+		t.Error("Expected an interface")
+	}
+
+	expectedNames := []string{"Close", "Get", "GetAll"}
+	if !reflect.DeepEqual(methodNames(typ), expectedNames) {
+		t.Errorf("Expected methods %v, got %v", expectedNames, methodNames(typ))
+	}
+
+	for _, m := range typ.Methods {
+		if m.Receiver != "Store" {
+			t.Errorf("Expected receiver 'Store' for %s, got '%s'", m.Name, m.Receiver)
+		}
+	}
+}
+
+func TestFindType_GenericInterface(t *testing.T) {
+	pkg := loadPackage(t, `
+package test
+type Repo[T any] interface {
+	Get(id string) (T, error)
+}
+`)
+
+	typ, err := pkg.FindType("Repo", Options{}, pkg.NewImports())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if typ.Methods[0].Receiver != "Repo[T]" || typ.Methods[0].ReceiverTypeArgs != "[T]" {
+		t.Errorf("Unexpected receiver %s %s", typ.Methods[0].Receiver, typ.Methods[0].ReceiverTypeArgs)
+	}
+}
+
+func TestFindType_TypeErrors(t *testing.T) {
+	pkg := loadPackage(t, `
+package test
+type TestStruct struct {}
+func (t *TestStruct) Valid() {}
+func unrelated() { undefinedFunction() }
+`)
+
+	if _, err := pkg.FindType("TestStruct", Options{}, pkg.NewImports()); err != nil {
+		t.Errorf("Expected unrelated type errors to be ignored, got %v", err)
+	}
+
+	pkg = loadPackage(t, `
+package test
+type TestStruct struct {}
+func (t *TestStruct) Invalid(u Undefined) {}
+`)
+
+	_, err := pkg.FindType("TestStruct", Options{}, pkg.NewImports())
+	if err == nil || !strings.Contains(err.Error(), "undefined: Undefined") {
+		t.Errorf("Expected error about undefined type, got %v", err)
+	}
+}
+
+func TestFindType_Unexported(t *testing.T) {
+	pkg := loadPackage(t, `
+package test
+type TestStruct struct {}
+func (t *TestStruct) Exported() {}
+func (t *TestStruct) unexported() {}
+`)
+
+	typ, err := pkg.FindType("TestStruct", Options{}, pkg.NewImports())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if expectedNames := []string{"Exported"}; !reflect.DeepEqual(methodNames(typ), expectedNames) {
+		t.Errorf("Expected methods %v, got %v", expectedNames, methodNames(typ))
+	}
+
+	typ, err = pkg.FindType("TestStruct", Options{IncludeUnexported: true}, pkg.NewImports())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if expectedNames := []string{"Exported", "unexported"}; !reflect.DeepEqual(methodNames(typ), expectedNames) {
+		t.Errorf("Expected methods %v, got %v", expectedNames, methodNames(typ))
+	}
+}
+
+func TestFindType_PromotedMethods(t *testing.T) {
+	pkg := loadPackage(t, `
+package test
+import "strings"
+type baseService struct {}
+func (b *baseService) Health() error { return nil }
+func (b baseService) Close() {}
+func (b baseService) Name() string { return "base" }
+type TestStruct struct {
+	baseService
+	*strings.Builder
+}
+func (t *TestStruct) Name() string { return "test" }
+`)
+
+	typ, err := pkg.FindType("TestStruct", Options{}, pkg.NewImports())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expectedNames := []string{"Name", "Cap", "Close", "Grow", "Health", "Len", "Reset", "String", "Write", "WriteByte", "WriteRune", "WriteString"}
+	if !reflect.DeepEqual(methodNames(typ), expectedNames) {
+		t.Errorf("Expected methods %v, got %v", expectedNames, methodNames(typ))
+	}
+
+	receivers := make(map[string]string)
+	for _, m := range typ.Methods {
+		receivers[m.Name] = m.Receiver
+	}
+	expectedReceivers := map[string]string{"Name": "*TestStruct", "Close": "baseService", "Health": "*baseService", "Len": "*strings.Builder"}
+	for name, expected := range expectedReceivers {
+		if receivers[name] != expected {
+			t.Errorf("Expected receiver '%s' for %s, got '%s'", expected, name, receivers[name])
+		}
+	}
+}
+
+func TestFindType_DelegateKind(t *testing.T) {
+	pkg := loadPackage(t, `
+package test
+type ValueStruct struct {}
+func (v ValueStruct) Get() string { return "" }
+type MixedStruct struct {}
+func (m MixedStruct) Get() string { return "" }
+func (m *MixedStruct) Set(value string) {}
+`)
+
+	testCases := []struct {
+		typeName              string
+		kind                  DelegateKind
+		expectedValueDelegate bool
+		expectedNames         []string
+	}{
+		{"ValueStruct", DelegatePointer, false, []string{"Get"}},
+		{"ValueStruct", DelegateValue, true, []string{"Get"}},
+		{"ValueStruct", DelegateAuto, true, []string{"Get"}},
+		{"MixedStruct", DelegateAuto, false, []string{"Get", "Set"}},
+	}
+
+	for _, tc := range testCases {
+		typ, err := pkg.FindType(tc.typeName, Options{DelegateKind: tc.kind}, pkg.NewImports())
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		if typ.ValueDelegate != tc.expectedValueDelegate {
+			t.Errorf("Expected value delegate %v for %s with kind %s, got %v", tc.expectedValueDelegate, tc.typeName, tc.kind, typ.ValueDelegate)
+		}
+
+		if !reflect.DeepEqual(methodNames(typ), tc.expectedNames) {
+			t.Errorf("Expected methods %v for %s with kind %s, got %v", tc.expectedNames, tc.typeName, tc.kind, methodNames(typ))
+		}
+	}
+
+	// Pointer receiver methods are only left out silently when deciding the delegate kind automatically.
+	_, err := pkg.FindType("MixedStruct", Options{DelegateKind: DelegateValue}, pkg.NewImports())
+	diagnostics, ok := err.(diag.List)
+	if !ok || len(diagnostics) != 1 || diagnostics[0].Line != 7 || diagnostics[0].Method != "Set" ||
+		diagnostics[0].Message != "has a pointer receiver, which a proxy holding its delegate by value can't call" {
+		t.Errorf("Expected a diagnostic for the pointer receiver of Set, got %v", err)
+	}
+}
+
+func TestFindType_Docs(t *testing.T) {
+	pkg := loadPackage(t, `
+package test
+type TestStruct struct {}
+// Documented is documented.
+func (t *TestStruct) Documented() {}
+func (t *TestStruct) Undocumented() {}
+type TestInterface interface {
+	// Documented is documented.
+	Documented()
+	Undocumented()
+}
+`)
+
+	for _, typeName := range []string{"TestStruct", "TestInterface"} {
+		typ, err := pkg.FindType(typeName, Options{}, pkg.NewImports())
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		if typ.Methods[0].Doc != "Documented is documented.\n" || typ.Methods[1].Doc != "" {
+			t.Errorf("Unexpected docs for %s: '%s' and '%s'", typeName, typ.Methods[0].Doc, typ.Methods[1].Doc)
+		}
+	}
+}
+
+func TestFindType_Positions(t *testing.T) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "/src/test/service.go", `
+package test
+import "strings"
+type TestStruct struct {
+	*strings.Builder
+}
+func (t *TestStruct) Get() {}
+`, parser.ParseComments)
+	if err != nil {
+		t.Fatalf("Unexpected error parsing source: %v", err)
+	}
+	pkg, err := Load("example.com/test", fset, []*ast.File{f}, importer.ForCompiler(fset, "source", nil))
+	if err != nil {
+		t.Fatalf("Unexpected error loading package: %v", err)
+	}
+
+	typ, err := pkg.FindType("TestStruct", Options{}, pkg.NewImports())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	positions := make(map[string]string)
+	for _, m := range typ.Methods {
+		positions[m.Name] = m.Position
+	}
+	// Methods are located by file name only, which doesn't depend on where the source is.
+	if positions["Get"] != "service.go:7" {
+		t.Errorf("Expected position 'service.go:7' for Get, got '%s'", positions["Get"])
+	}
+	if !strings.HasPrefix(positions["Len"], "builder.go:") {
+		t.Errorf("Expected position in builder.go for Len, got '%s'", positions["Len"])
+	}
+}
+
+func TestTypeNames(t *testing.T) {
+	pkg := loadPackage(t, `
+package test
+type UserService struct {}
+func (s *UserService) Get() {}
+type OrderService interface { Get() }
+type EmptyService struct {}
+type AliasService = UserService
+type Order struct {}
+`, `
+package test
+
+// Code generated by a generator. DO NOT EDIT.
+
+type UserServiceProxy struct {}
+`)
+
+	typeNames, err := pkg.TypeNames([]string{"Order", "*Service*", "UserService"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if expected := []string{"Order", "OrderService", "UserService"}; !reflect.DeepEqual(typeNames, expected) {
+		t.Errorf("Expected type names %v, got %v", expected, typeNames)
+	}
+
+	_, err = pkg.TypeNames([]string{"*Repository"})
+	if err == nil || err.Error() != "no type matches pattern *Repository" {
+		t.Errorf("Expected error about unmatched pattern, got %v", err)
+	}
+}
+
+func TestFindType_External(t *testing.T) {
+	pkg := loadPackage(t, `
+package test
+type Struct struct {}
+type TestStruct struct {}
+func (t *TestStruct) Get(s Struct) *Struct { return nil }
+func (t *TestStruct) unexported() {}
+type hidden struct {}
+type OtherStruct struct {}
+func (o *OtherStruct) Hide(h []hidden) {}
+`)
+
+	imports := pkg.NewExternalImports("example.com/proxies")
+	typ, err := pkg.FindType("TestStruct", Options{IncludeUnexported: true}, imports)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if typ.Reference != "test.TestStruct" {
+		t.Errorf("Expected reference test.TestStruct, got %s", typ.Reference)
+	}
+
+	if expectedNames := []string{"Get"}; !reflect.DeepEqual(methodNames(typ), expectedNames) {
+		t.Errorf("Expected methods %v, got %v", expectedNames, methodNames(typ))
+	}
+
+	if typ.Methods[0].Params != "s test.Struct" || typ.Methods[0].Results != "*test.Struct" {
+		t.Errorf("Unexpected signature (%s) %s", typ.Methods[0].Params, typ.Methods[0].Results)
+	}
+
+	if expectedImports := []string{`test "example.com/test"`}; !reflect.DeepEqual(imports.Specs(), expectedImports) {
+		t.Errorf("Expected imports %v, got %v", expectedImports, imports.Specs())
+	}
+
+	_, err = pkg.FindType("OtherStruct", Options{}, pkg.NewExternalImports("example.com/proxies"))
+	expectedErrMsg := "method Hide: refers to unexported type hidden, which can't be referenced from another package"
+	if err == nil || err.Error() != expectedErrMsg {
+		t.Errorf("Expected error '%s', got '%v'", expectedErrMsg, err)
+	}
+
+	_, err = pkg.FindType("hidden", Options{}, pkg.NewExternalImports("example.com/proxies"))
+	expectedErrMsg = "cannot proxy unexported type hidden from another package"
+	if err == nil || err.Error() != expectedErrMsg {
+		t.Errorf("Expected error '%s', got '%v'", expectedErrMsg, err)
+	}
+}
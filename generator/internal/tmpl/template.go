@@ -5,42 +5,207 @@ import (
 	_ "embed"
 	"fmt"
 	"github.com/LeMikaelF/proxy-generator/generator/internal/method"
+	"github.com/LeMikaelF/proxy-generator/generator/internal/source"
 	"go/format"
+	"strings"
 	"text/template"
 )
 
 type Template struct {
-	packageName string
-	structName  string
-	methods     []method.Method
-	imports     []string
+	packageName   string
+	sourcePackage string
+	helpers       string
+	imports       []string
+	proxies       []Proxy
 }
 
-func New(packageName string, structName string, methods []method.Method, imports []string) *Template {
-	return &Template{packageName: packageName, structName: structName, methods: methods, imports: imports}
+// Proxy describes a proxy to generate.
+type Proxy struct {
+	Type *source.Type
+	// Implements lists the interfaces which the proxy is asserted to implement.
+	Implements []string
+	// InterfaceName, if not empty, is the name of an interface to generate with the proxied methods.
+	InterfaceName string
+	// Hooks generates a proxy calling typed hook funcs around each method, rather than an invocation handler.
+	Hooks bool
+	// Middleware generates typed middleware for each method, called before the invocation handler.
+	Middleware bool
+}
+
+// New prepares a file of package packageName containing proxies of types from sourcePackage, which share their helper
+// declarations, named after helpers.
+func New(packageName string, sourcePackage string, helpers string, imports []string, proxies ...Proxy) *Template {
+	return &Template{packageName: packageName, sourcePackage: sourcePackage, helpers: helpers, imports: imports, proxies: proxies}
 }
 
 //go:embed proxy.tmpl
 var proxyTemplate string
 
+//go:embed hooks.tmpl
+var hooksTemplate string
+
 type data struct {
-	PackageName string
-	StructName  string
-	ProxyName   string
-	Methods     []method.Method
-	Imports     []string
+	PackageName   string
+	SourcePackage string
+	Imports       []string
+	// Helpers is the name prefixing the helper declarations shared by the proxies of a file.
+	Helpers string
+	// Handlers is set when a proxy of the file calls an invocation handler, which needs the helper declarations.
+	Handlers bool
+	// Middleware is set when a proxy of the file has typed middleware, which needs the helper composing it.
+	Middleware bool
+	Proxies    []proxyData
+}
+
+type proxyData struct {
+	Helpers       string
+	StructName    string
+	ProxyName     string
+	TypeParams    string
+	TypeArgs      string
+	DelegateType  string
+	ValueProxy    bool
+	Methods       []method.Method
+	Implements    []string
+	InterfaceName string
+	Hooks         bool
+	Middleware    bool
+	// StructAssertion is an expression of the proxied type which must implement the generated interface.
+	StructAssertion string
+}
+
+var funcs = template.FuncMap{
+	"comment":          comment,
+	"beforeHook":       beforeHook,
+	"afterHook":        afterHook,
+	"beforeAssignment": beforeAssignment,
+	"resultNames":      resultNames,
+}
+
+// comment turns text into line comments, each followed by a newline.
+func comment(text string) string {
+	if text == "" {
+		return ""
+	}
+
+	var b strings.Builder
+	for _, line := range strings.Split(strings.TrimSuffix(text, "\n"), "\n") {
+		if line == "" {
+			b.WriteString("//\n")
+		} else {
+			b.WriteString("// " + line + "\n")
+		}
+	}
+	return b.String()
+}
+
+// beforeHook returns the type of the hook called before m, which returns the arguments of m, and an error if m returns
+// one.
+func beforeHook(m method.Method) string {
+	results := append([]string(nil), m.ParamTypes...)
+	if m.Signature.ErrorIndex >= 0 {
+		results = append(results, "error")
+	}
+	return "func(" + hookParams(m) + ")" + resultList(results)
+}
+
+// afterHook returns the type of the hook called after m, which returns the results of m.
+func afterHook(m method.Method) string {
+	params := hookParams(m)
+	for i, resultType := range m.ResultTypes {
+		if params != "" {
+			params += ", "
+		}
+		params += fmt.Sprintf("%s%d %s", m.Identifiers.Result, i, resultType)
+	}
+	return "func(" + params + ")" + resultList(m.ResultTypes)
+}
+
+// hookParams returns the parameters of m as received by its hooks, which receive a variadic parameter as a slice.
+func hookParams(m method.Method) string {
+	params := make([]string, 0, len(m.ParamTypes))
+	for i, paramType := range m.ParamTypes {
+		params = append(params, m.Signature.ParamNames[i]+" "+paramType)
+	}
+	return strings.Join(params, ", ")
+}
+
+func resultList(types []string) string {
+	switch len(types) {
+	case 0:
+		return ""
+	case 1:
+		return " " + types[0]
+	default:
+		return " (" + strings.Join(types, ", ") + ")"
+	}
+}
+
+// beforeAssignment returns the variables assigned the results of the hook called before m.
+func beforeAssignment(m method.Method) string {
+	names := append([]string(nil), m.Signature.ParamNames...)
+	if m.Signature.ErrorIndex >= 0 {
+		names = append(names, fmt.Sprintf("%s%d", m.Identifiers.Result, m.Signature.ErrorIndex))
+	}
+	return strings.Join(names, ", ")
+}
+
+// resultNames returns the variables holding the results of m.
+func resultNames(m method.Method) string {
+	names := make([]string, 0, len(m.ResultTypes))
+	for i := range m.ResultTypes {
+		names = append(names, fmt.Sprintf("%s%d", m.Identifiers.Result, i))
+	}
+	return strings.Join(names, ", ")
+}
+
+// delegateType returns the type of the proxy's delegate. Interfaces and value delegates are held as is, while other
+// structs are held by pointer.
+func delegateType(proxied *source.Type) string {
+	if proxied.IsInterface || proxied.ValueDelegate {
+		return proxied.Reference + proxied.TypeArgs
+	}
+	return "*" + proxied.Reference + proxied.TypeArgs
+}
+
+// structAssertion returns a nil value of the proxied type. Structs are asserted by pointer, whose method set includes
+// the value receiver methods.
+func structAssertion(proxied *source.Type) string {
+	if proxied.IsInterface {
+		return proxied.Reference + "(nil)"
+	}
+	return "(*" + proxied.Reference + ")(nil)"
 }
 
 func (t *Template) Render() ([]byte, error) {
-	var buf bytes.Buffer
-	err := template.Must(template.New("proxy").Parse(proxyTemplate)).
-		Execute(&buf, data{
-			PackageName: t.packageName,
-			StructName:  t.structName,
-			ProxyName:   t.structName + "Proxy",
-			Methods:     t.methods,
-			Imports:     t.imports,
+	d := data{
+		PackageName:   t.packageName,
+		SourcePackage: t.sourcePackage,
+		Imports:       t.imports,
+		Helpers:       t.helpers,
+	}
+	for _, proxy := range t.proxies {
+		d.Proxies = append(d.Proxies, proxyData{
+			Helpers:         d.Helpers,
+			StructName:      proxy.Type.Name,
+			ProxyName:       proxy.Type.Name + "Proxy",
+			TypeParams:      proxy.Type.TypeParams,
+			TypeArgs:        proxy.Type.TypeArgs,
+			DelegateType:    delegateType(proxy.Type),
+			ValueProxy:      proxy.Type.ValueDelegate,
+			Methods:         proxy.Type.Methods,
+			Implements:      proxy.Implements,
+			InterfaceName:   proxy.InterfaceName,
+			Hooks:           proxy.Hooks,
+			Middleware:      proxy.Middleware,
+			StructAssertion: structAssertion(proxy.Type),
 		})
+		d.Handlers = d.Handlers || !proxy.Hooks
+		d.Middleware = d.Middleware || proxy.Middleware
+	}
+
+	var buf bytes.Buffer
+	err := template.Must(template.Must(template.New("file").Funcs(funcs).Parse(proxyTemplate)).Parse(hooksTemplate)).Execute(&buf, d)
 	if err != nil {
 		return nil, fmt.Errorf("error executing template: %v", err)
 	}
@@ -3,6 +3,7 @@ package main
 import (
 	"github.com/LeMikaelF/proxy-generator/generator"
 	"log"
+	"os"
 )
 
 func main() {
@@ -13,6 +14,7 @@ func main() {
 
 	err = gen.Run()
 	if err != nil {
-		log.Fatalf("could not not generator: %v\n", err)
+		gen.Report(os.Stderr, err)
+		os.Exit(1)
 	}
 }
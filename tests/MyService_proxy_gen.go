@@ -5,8 +5,9 @@ package tests
 import (
 	context "context"
 	xml "encoding/xml"
+	fmt "fmt"
 	constraint "go/build/constraint"
-	alias "net/http/httptest"
+	httptest "net/http/httptest"
 )
 
 type MyServiceProxy struct {
@@ -17,124 +18,186 @@ type MyServiceProxy struct {
 		Name() string
 		Invoke(args []any) []any
 	}, args []any) []any
+	invocations []_MyServiceProxyInvocation
 }
 
-type _MyServiceMethod struct {
-	methodName string
-	receiver   string
-	method     func([]any) []any
+// Service is the set of methods of MyService proxied by MyServiceProxy.
+type Service interface {
+	NoArgsMethod()
+	ContextMethod(ctx context.Context)
+	PassthroughMethod() error
+
+	// OneArgErrorMethod returns no error.
+	OneArgErrorMethod() error
+	TwoArgsErrorMethod(ctx context.Context, aStruct Struct) (string, error)
+	ArgsWithComplexImportPathsAndAlias(a xml.CharData, b constraint.Expr, server httptest.ResponseRecorder)
+	Health() error
 }
 
-func (m *_MyServiceMethod) Name() string { return m.methodName }
-
-func (m *_MyServiceMethod) Receiver() string { return m.receiver }
-
-func (m *_MyServiceMethod) Package() string { return "tests" }
+var _ Service = (*MyService)(nil)
 
-func (m *_MyServiceMethod) Invoke(args []any) []any { return m.method(args) }
+var _ Service = (*MyServiceProxy)(nil)
 
 func (d *MyServiceProxy) NoArgsMethod() {
 
-	method := _MyServiceMethod{
-		methodName: "NoArgsMethod",
-		receiver:   "*MyService",
-		method: func(args []any) []any {
-			d.delegate.NoArgsMethod()
-			return []any{}
-		},
-	}
-
 	var args []any
-	d.invocationHandler(&method, args)
+	d.invocationHandler(&d.invocations[0], args)
 
 }
 
 func (d *MyServiceProxy) ContextMethod(ctx context.Context) {
 
-	method := _MyServiceMethod{
-		methodName: "ContextMethod",
-		receiver:   "*MyService",
-		method: func(args []any) []any {
-			d.delegate.ContextMethod(args[0].(context.Context))
-			return []any{}
-		},
-	}
-
 	var args []any = []any{ctx}
-	d.invocationHandler(&method, args)
+	d.invocationHandler(&d.invocations[1], args)
 
 }
 
-func (d *MyServiceProxy) unexportedMethod() {
+func (d *MyServiceProxy) PassthroughMethod() error {
 
-	method := _MyServiceMethod{
-		methodName: "unexportedMethod",
-		receiver:   "*MyService",
-		method: func(args []any) []any {
-			d.delegate.unexportedMethod()
-			return []any{}
-		},
-	}
+	return d.delegate.PassthroughMethod()
+
+}
+
+func (d *MyServiceProxy) OneArgErrorMethod() error {
 
 	var args []any
-	d.invocationHandler(&method, args)
+	results := d.invocationHandler(&d.invocations[3], args)
+	_MyServiceCheckResults(results, 1, "MyService.OneArgErrorMethod")
+	return _MyServiceResult[error](results, 0, "MyService.OneArgErrorMethod", "error")
 
 }
 
-func (d *MyServiceProxy) PassthroughMethod() error {
+func (d *MyServiceProxy) TwoArgsErrorMethod(ctx context.Context, aStruct Struct) (string, error) {
 
-	return d.delegate.PassthroughMethod()
+	var args []any = []any{ctx, aStruct}
+	results := d.invocationHandler(&d.invocations[4], args)
+	_MyServiceCheckResults(results, 2, "MyService.TwoArgsErrorMethod")
+	return _MyServiceResult[string](results, 0, "MyService.TwoArgsErrorMethod", "string"), _MyServiceResult[error](results, 1, "MyService.TwoArgsErrorMethod", "error")
 
 }
 
-func (d *MyServiceProxy) OneArgErrorMethod() error {
+func (d *MyServiceProxy) ArgsWithComplexImportPathsAndAlias(a xml.CharData, b constraint.Expr, server httptest.ResponseRecorder) {
 
-	method := _MyServiceMethod{
-		methodName: "OneArgErrorMethod",
-		receiver:   "*MyService",
-		method: func(args []any) []any {
-			result0 := d.delegate.OneArgErrorMethod()
-			return []any{result0}
-		},
-	}
+	var args []any = []any{a, b, server}
+	d.invocationHandler(&d.invocations[5], args)
+
+}
+
+func (d *MyServiceProxy) Health() error {
 
 	var args []any
-	results := d.invocationHandler(&method, args)
-	return results[0].(error)
+	results := d.invocationHandler(&d.invocations[6], args)
+	_MyServiceCheckResults(results, 1, "MyService.Health")
+	return _MyServiceResult[error](results, 0, "MyService.Health", "error")
 
 }
 
-func (d *MyServiceProxy) TwoArgsErrorMethod(ctx context.Context, aStruct Struct) (string, error) {
+// _MyServiceProxyMethods describes the methods of MyServiceProxy, in the order of its invocations.
+var _MyServiceProxyMethods = [...]_MyServiceMethod{
+	{
+		methodName:   "NoArgsMethod",
+		receiver:     "*MyService",
+		errorIndex:   -1,
+		contextIndex: -1,
+		position:     "myservice.go:31",
+	},
+	{
+		methodName:   "ContextMethod",
+		receiver:     "*MyService",
+		paramNames:   []string{"ctx"},
+		paramTypes:   []string{"context.Context"},
+		errorIndex:   -1,
+		contextIndex: 0,
+		position:     "myservice.go:33",
+	},
+	{
+		methodName:   "PassthroughMethod",
+		receiver:     "*MyService",
+		resultTypes:  []string{"error"},
+		errorIndex:   0,
+		contextIndex: -1,
+		position:     "myservice.go:37",
+	},
+	{
+		methodName:   "OneArgErrorMethod",
+		receiver:     "*MyService",
+		resultTypes:  []string{"error"},
+		errorIndex:   0,
+		contextIndex: -1,
+		position:     "myservice.go:42",
+	},
+	{
+		methodName:   "TwoArgsErrorMethod",
+		receiver:     "*MyService",
+		paramNames:   []string{"ctx", "aStruct"},
+		paramTypes:   []string{"context.Context", "Struct"},
+		resultTypes:  []string{"string", "error"},
+		errorIndex:   1,
+		contextIndex: 0,
+		position:     "myservice.go:46",
+	},
+	{
+		methodName:   "ArgsWithComplexImportPathsAndAlias",
+		receiver:     "*MyService",
+		paramNames:   []string{"a", "b", "server"},
+		paramTypes:   []string{"xml.CharData", "constraint.Expr", "httptest.ResponseRecorder"},
+		errorIndex:   -1,
+		contextIndex: -1,
+		position:     "myservice.go:50",
+	},
+	{
+		methodName:   "Health",
+		receiver:     "*baseService",
+		resultTypes:  []string{"error"},
+		errorIndex:   0,
+		contextIndex: -1,
+		position:     "myservice.go:27",
+	},
+}
 
-	method := _MyServiceMethod{
-		methodName: "TwoArgsErrorMethod",
-		receiver:   "*MyService",
-		method: func(args []any) []any {
-			result0, result1 := d.delegate.TwoArgsErrorMethod(args[0].(context.Context), args[1].(Struct))
-			return []any{result0, result1}
-		},
-	}
+// _MyServiceProxyInvocation binds the descriptor of a method to the delegate of a proxy, so that proxied calls don't
+// allocate it.
+type _MyServiceProxyInvocation struct {
+	*_MyServiceMethod
+	delegate *MyService
+	invoke   func(*_MyServiceProxyInvocation, []any) []any
+}
 
-	var args []any = []any{ctx, aStruct}
-	results := d.invocationHandler(&method, args)
-	return results[0].(string), results[1].(error)
+func (i *_MyServiceProxyInvocation) Invoke(args []any) []any { return i.invoke(i, args) }
 
+func (d *_MyServiceProxyInvocation) invokeNoArgsMethod(args []any) []any {
+	d.delegate.NoArgsMethod()
+	return []any{}
 }
 
-func (d *MyServiceProxy) ArgsWithComplexImportPathsAndAlias(a xml.CharData, b constraint.Expr, server alias.ResponseRecorder) {
+func (d *_MyServiceProxyInvocation) invokeContextMethod(args []any) []any {
+	d.delegate.ContextMethod(args[0].(context.Context))
+	return []any{}
+}
 
-	method := _MyServiceMethod{
-		methodName: "ArgsWithComplexImportPathsAndAlias",
-		receiver:   "*MyService",
-		method: func(args []any) []any {
-			d.delegate.ArgsWithComplexImportPathsAndAlias(args[0].(xml.CharData), args[1].(constraint.Expr), args[2].(alias.ResponseRecorder))
-			return []any{}
-		},
-	}
+func (d *_MyServiceProxyInvocation) invokePassthroughMethod(args []any) []any {
+	result0 := d.delegate.PassthroughMethod()
+	return []any{result0}
+}
 
-	var args []any = []any{a, b, server}
-	d.invocationHandler(&method, args)
+func (d *_MyServiceProxyInvocation) invokeOneArgErrorMethod(args []any) []any {
+	result0 := d.delegate.OneArgErrorMethod()
+	return []any{result0}
+}
+
+func (d *_MyServiceProxyInvocation) invokeTwoArgsErrorMethod(args []any) []any {
+	result0, result1 := d.delegate.TwoArgsErrorMethod(args[0].(context.Context), args[1].(Struct))
+	return []any{result0, result1}
+}
+
+func (d *_MyServiceProxyInvocation) invokeArgsWithComplexImportPathsAndAlias(args []any) []any {
+	d.delegate.ArgsWithComplexImportPathsAndAlias(args[0].(xml.CharData), args[1].(constraint.Expr), args[2].(httptest.ResponseRecorder))
+	return []any{}
+}
 
+func (d *_MyServiceProxyInvocation) invokeHealth(args []any) []any {
+	result0 := d.delegate.Health()
+	return []any{result0}
 }
 
 func NewMyServiceProxy(delegate *MyService, invocationHandler func(method interface {
@@ -157,5 +220,86 @@ func NewMyServiceProxy(delegate *MyService, invocationHandler func(method interf
 	return &MyServiceProxy{
 		delegate:          delegate,
 		invocationHandler: invocationHandler,
+		invocations: []_MyServiceProxyInvocation{
+			{&_MyServiceProxyMethods[0], delegate, (*_MyServiceProxyInvocation).invokeNoArgsMethod},
+			{&_MyServiceProxyMethods[1], delegate, (*_MyServiceProxyInvocation).invokeContextMethod},
+			{&_MyServiceProxyMethods[2], delegate, (*_MyServiceProxyInvocation).invokePassthroughMethod},
+			{&_MyServiceProxyMethods[3], delegate, (*_MyServiceProxyInvocation).invokeOneArgErrorMethod},
+			{&_MyServiceProxyMethods[4], delegate, (*_MyServiceProxyInvocation).invokeTwoArgsErrorMethod},
+			{&_MyServiceProxyMethods[5], delegate, (*_MyServiceProxyInvocation).invokeArgsWithComplexImportPathsAndAlias},
+			{&_MyServiceProxyMethods[6], delegate, (*_MyServiceProxyInvocation).invokeHealth},
+		},
+	}
+}
+
+// _MyServiceMethod describes a proxied method. Descriptors are package-level values, shared by every proxy, so they
+// return copies of their slices and maps, which handlers may modify.
+type _MyServiceMethod struct {
+	methodName   string
+	receiver     string
+	tags         map[string]string
+	paramNames   []string
+	paramTypes   []string
+	resultTypes  []string
+	variadic     bool
+	errorIndex   int
+	contextIndex int
+	position     string
+}
+
+func (m *_MyServiceMethod) Name() string { return m.methodName }
+
+func (m *_MyServiceMethod) Receiver() string { return m.receiver }
+
+func (m *_MyServiceMethod) Package() string { return "tests" }
+
+// Tags returns the tags of the method, set by //proxy:tag directives or a tags file. Invocation handlers can read them
+// with a type assertion to interface { Tags() map[string]string }.
+func (m *_MyServiceMethod) Tags() map[string]string {
+	if m.tags == nil {
+		return nil
+	}
+	tags := make(map[string]string, len(m.tags))
+	for key, value := range m.tags {
+		tags[key] = value
+	}
+	return tags
+}
+
+// ParamNames returns the names of the method's parameters, in the order of args. Unnamed parameters are named after their
+// position, as in p0.
+func (m *_MyServiceMethod) ParamNames() []string { return append([]string(nil), m.paramNames...) }
+
+// ParamTypes returns the declared types of the method's parameters, such as ...string for a variadic parameter, which
+// is passed in args as a slice.
+func (m *_MyServiceMethod) ParamTypes() []string { return append([]string(nil), m.paramTypes...) }
+
+// ResultTypes returns the declared types of the method's results.
+func (m *_MyServiceMethod) ResultTypes() []string { return append([]string(nil), m.resultTypes...) }
+
+// Variadic reports whether the method's last parameter is variadic.
+func (m *_MyServiceMethod) Variadic() bool { return m.variadic }
+
+// ErrorIndex returns the index of the method's trailing error result, or -1 if it doesn't return an error.
+func (m *_MyServiceMethod) ErrorIndex() int { return m.errorIndex }
+
+// ContextIndex returns the index of the method's leading context.Context parameter, or -1 if it doesn't take one.
+func (m *_MyServiceMethod) ContextIndex() int { return m.contextIndex }
+
+// Position returns the file:line of the method's declaration, or an empty string if it's unknown.
+func (m *_MyServiceMethod) Position() string { return m.position }
+
+func _MyServiceCheckResults(results []any, count int, methodName string) {
+	if len(results) != count {
+		panic(fmt.Sprintf("invocation handler returned %d results for %s, expected %d", len(results), methodName, count))
+	}
+}
+
+func _MyServiceResult[T any](results []any, index int, methodName string, typeName string) T {
+	// A nil result, such as a nil error, converts to the zero value of its declared type.
+	result, ok := results[index].(T)
+	if !ok && results[index] != nil {
+		panic(fmt.Sprintf("invocation handler returned %T at index %d for %s, expected %s", results[index], index, methodName, typeName))
 	}
+	return result
 }
@@ -0,0 +1,139 @@
+package hooks
+
+// Code generated by Mikaël's proxy generator. DO NOT EDIT.
+
+import (
+	context "context"
+	xml "encoding/xml"
+	tests "github.com/LeMikaelF/proxy-generator/tests"
+	constraint "go/build/constraint"
+	httptest "net/http/httptest"
+)
+
+// MyServiceHooks holds the hooks called by MyServiceProxy around the methods of MyService.
+//
+// Before hooks receive the arguments of a method and return them, possibly replaced, along with an error for methods
+// returning one, which the method then returns without calling the delegate. After hooks receive the arguments and
+// results of a method, and return its results, possibly replaced. Nil hooks are skipped.
+type MyServiceHooks struct {
+	BeforeNoArgsMethod                       func()
+	AfterNoArgsMethod                        func()
+	BeforeContextMethod                      func(ctx context.Context) context.Context
+	AfterContextMethod                       func(ctx context.Context)
+	BeforeOneArgErrorMethod                  func() error
+	AfterOneArgErrorMethod                   func(result0 error) error
+	BeforeTwoArgsErrorMethod                 func(ctx context.Context, aStruct tests.Struct) (context.Context, tests.Struct, error)
+	AfterTwoArgsErrorMethod                  func(ctx context.Context, aStruct tests.Struct, result0 string, result1 error) (string, error)
+	BeforeArgsWithComplexImportPathsAndAlias func(a xml.CharData, b constraint.Expr, server httptest.ResponseRecorder) (xml.CharData, constraint.Expr, httptest.ResponseRecorder)
+	AfterArgsWithComplexImportPathsAndAlias  func(a xml.CharData, b constraint.Expr, server httptest.ResponseRecorder)
+	BeforeHealth                             func() error
+	AfterHealth                              func(result0 error) error
+}
+
+type MyServiceProxy struct {
+	delegate *tests.MyService
+	hooks    MyServiceHooks
+}
+
+func (d *MyServiceProxy) NoArgsMethod() {
+
+	if d.hooks.BeforeNoArgsMethod != nil {
+		d.hooks.BeforeNoArgsMethod()
+	}
+	d.delegate.NoArgsMethod()
+	if d.hooks.AfterNoArgsMethod != nil {
+		d.hooks.AfterNoArgsMethod()
+	}
+
+}
+
+func (d *MyServiceProxy) ContextMethod(ctx context.Context) {
+
+	if d.hooks.BeforeContextMethod != nil {
+		ctx = d.hooks.BeforeContextMethod(ctx)
+	}
+	d.delegate.ContextMethod(ctx)
+	if d.hooks.AfterContextMethod != nil {
+		d.hooks.AfterContextMethod(ctx)
+	}
+
+}
+
+func (d *MyServiceProxy) PassthroughMethod() error {
+
+	return d.delegate.PassthroughMethod()
+
+}
+
+func (d *MyServiceProxy) OneArgErrorMethod() error {
+
+	var result0 error
+	if d.hooks.BeforeOneArgErrorMethod != nil {
+		result0 = d.hooks.BeforeOneArgErrorMethod()
+		if result0 != nil {
+			return result0
+		}
+	}
+	result0 = d.delegate.OneArgErrorMethod()
+	if d.hooks.AfterOneArgErrorMethod != nil {
+		result0 = d.hooks.AfterOneArgErrorMethod(result0)
+	}
+	return result0
+
+}
+
+func (d *MyServiceProxy) TwoArgsErrorMethod(ctx context.Context, aStruct tests.Struct) (string, error) {
+
+	var (
+		result0 string
+		result1 error
+	)
+	if d.hooks.BeforeTwoArgsErrorMethod != nil {
+		ctx, aStruct, result1 = d.hooks.BeforeTwoArgsErrorMethod(ctx, aStruct)
+		if result1 != nil {
+			return result0, result1
+		}
+	}
+	result0, result1 = d.delegate.TwoArgsErrorMethod(ctx, aStruct)
+	if d.hooks.AfterTwoArgsErrorMethod != nil {
+		result0, result1 = d.hooks.AfterTwoArgsErrorMethod(ctx, aStruct, result0, result1)
+	}
+	return result0, result1
+
+}
+
+func (d *MyServiceProxy) ArgsWithComplexImportPathsAndAlias(a xml.CharData, b constraint.Expr, server httptest.ResponseRecorder) {
+
+	if d.hooks.BeforeArgsWithComplexImportPathsAndAlias != nil {
+		a, b, server = d.hooks.BeforeArgsWithComplexImportPathsAndAlias(a, b, server)
+	}
+	d.delegate.ArgsWithComplexImportPathsAndAlias(a, b, server)
+	if d.hooks.AfterArgsWithComplexImportPathsAndAlias != nil {
+		d.hooks.AfterArgsWithComplexImportPathsAndAlias(a, b, server)
+	}
+
+}
+
+func (d *MyServiceProxy) Health() error {
+
+	var result0 error
+	if d.hooks.BeforeHealth != nil {
+		result0 = d.hooks.BeforeHealth()
+		if result0 != nil {
+			return result0
+		}
+	}
+	result0 = d.delegate.Health()
+	if d.hooks.AfterHealth != nil {
+		result0 = d.hooks.AfterHealth(result0)
+	}
+	return result0
+
+}
+
+func NewMyServiceProxy(delegate *tests.MyService, hooks MyServiceHooks) *MyServiceProxy {
+	return &MyServiceProxy{
+		delegate: delegate,
+		hooks:    hooks,
+	}
+}
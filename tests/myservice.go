@@ -8,14 +8,24 @@ import (
 	alias "net/http/httptest"
 )
 
-//go:generate go run ../main.go --type MyService --passthrough-methods PassthroughMethod myservice.go
+//go:generate go run ../main.go --type MyService --passthrough-methods PassthroughMethod --emit-interface Service myservice.go
+//go:generate go run ../main.go --type MyService --passthrough-methods PassthroughMethod --output proxies/MyService_proxy_gen.go --output-package github.com/LeMikaelF/proxy-generator/tests/proxies
+//go:generate go run ../main.go --type MyService --passthrough-methods PassthroughMethod --mode hooks --output hooks/MyService_proxy_gen.go --output-package github.com/LeMikaelF/proxy-generator/tests/hooks
+//go:generate go run ../main.go --type MyService --passthrough-methods PassthroughMethod --middleware --output middleware/MyService_proxy_gen.go --output-package github.com/LeMikaelF/proxy-generator/tests/middleware
 type MyService struct {
+	baseService
 	param1 string
 	param2 string
 }
 
 func NewMyService(param1 string, param2 string) *MyService {
-	return &MyService{param1, param2}
+	return &MyService{param1: param1, param2: param2}
+}
+
+type baseService struct{}
+
+func (b *baseService) Health() error {
+	return nil
 }
 
 func (s *MyService) NoArgsMethod() {}
@@ -28,6 +38,7 @@ func (s *MyService) PassthroughMethod() error {
 	return nil
 }
 
+// OneArgErrorMethod returns no error.
 func (s *MyService) OneArgErrorMethod() error {
 	return nil
 }
@@ -0,0 +1,289 @@
+package proxies
+
+// Code generated by Mikaël's proxy generator. DO NOT EDIT.
+
+import (
+	context "context"
+	xml "encoding/xml"
+	fmt "fmt"
+	tests "github.com/LeMikaelF/proxy-generator/tests"
+	constraint "go/build/constraint"
+	httptest "net/http/httptest"
+)
+
+type MyServiceProxy struct {
+	delegate          *tests.MyService
+	invocationHandler func(method interface {
+		Package() string
+		Receiver() string
+		Name() string
+		Invoke(args []any) []any
+	}, args []any) []any
+	invocations []_MyServiceProxyInvocation
+}
+
+func (d *MyServiceProxy) NoArgsMethod() {
+
+	var args []any
+	d.invocationHandler(&d.invocations[0], args)
+
+}
+
+func (d *MyServiceProxy) ContextMethod(ctx context.Context) {
+
+	var args []any = []any{ctx}
+	d.invocationHandler(&d.invocations[1], args)
+
+}
+
+func (d *MyServiceProxy) PassthroughMethod() error {
+
+	return d.delegate.PassthroughMethod()
+
+}
+
+func (d *MyServiceProxy) OneArgErrorMethod() error {
+
+	var args []any
+	results := d.invocationHandler(&d.invocations[3], args)
+	_MyServiceCheckResults(results, 1, "MyService.OneArgErrorMethod")
+	return _MyServiceResult[error](results, 0, "MyService.OneArgErrorMethod", "error")
+
+}
+
+func (d *MyServiceProxy) TwoArgsErrorMethod(ctx context.Context, aStruct tests.Struct) (string, error) {
+
+	var args []any = []any{ctx, aStruct}
+	results := d.invocationHandler(&d.invocations[4], args)
+	_MyServiceCheckResults(results, 2, "MyService.TwoArgsErrorMethod")
+	return _MyServiceResult[string](results, 0, "MyService.TwoArgsErrorMethod", "string"), _MyServiceResult[error](results, 1, "MyService.TwoArgsErrorMethod", "error")
+
+}
+
+func (d *MyServiceProxy) ArgsWithComplexImportPathsAndAlias(a xml.CharData, b constraint.Expr, server httptest.ResponseRecorder) {
+
+	var args []any = []any{a, b, server}
+	d.invocationHandler(&d.invocations[5], args)
+
+}
+
+func (d *MyServiceProxy) Health() error {
+
+	var args []any
+	results := d.invocationHandler(&d.invocations[6], args)
+	_MyServiceCheckResults(results, 1, "MyService.Health")
+	return _MyServiceResult[error](results, 0, "MyService.Health", "error")
+
+}
+
+// _MyServiceProxyMethods describes the methods of MyServiceProxy, in the order of its invocations.
+var _MyServiceProxyMethods = [...]_MyServiceMethod{
+	{
+		methodName:   "NoArgsMethod",
+		receiver:     "*MyService",
+		errorIndex:   -1,
+		contextIndex: -1,
+		position:     "myservice.go:31",
+	},
+	{
+		methodName:   "ContextMethod",
+		receiver:     "*MyService",
+		paramNames:   []string{"ctx"},
+		paramTypes:   []string{"context.Context"},
+		errorIndex:   -1,
+		contextIndex: 0,
+		position:     "myservice.go:33",
+	},
+	{
+		methodName:   "PassthroughMethod",
+		receiver:     "*MyService",
+		resultTypes:  []string{"error"},
+		errorIndex:   0,
+		contextIndex: -1,
+		position:     "myservice.go:37",
+	},
+	{
+		methodName:   "OneArgErrorMethod",
+		receiver:     "*MyService",
+		resultTypes:  []string{"error"},
+		errorIndex:   0,
+		contextIndex: -1,
+		position:     "myservice.go:42",
+	},
+	{
+		methodName:   "TwoArgsErrorMethod",
+		receiver:     "*MyService",
+		paramNames:   []string{"ctx", "aStruct"},
+		paramTypes:   []string{"context.Context", "Struct"},
+		resultTypes:  []string{"string", "error"},
+		errorIndex:   1,
+		contextIndex: 0,
+		position:     "myservice.go:46",
+	},
+	{
+		methodName:   "ArgsWithComplexImportPathsAndAlias",
+		receiver:     "*MyService",
+		paramNames:   []string{"a", "b", "server"},
+		paramTypes:   []string{"xml.CharData", "constraint.Expr", "httptest.ResponseRecorder"},
+		errorIndex:   -1,
+		contextIndex: -1,
+		position:     "myservice.go:50",
+	},
+	{
+		methodName:   "Health",
+		receiver:     "*baseService",
+		resultTypes:  []string{"error"},
+		errorIndex:   0,
+		contextIndex: -1,
+		position:     "myservice.go:27",
+	},
+}
+
+// _MyServiceProxyInvocation binds the descriptor of a method to the delegate of a proxy, so that proxied calls don't
+// allocate it.
+type _MyServiceProxyInvocation struct {
+	*_MyServiceMethod
+	delegate *tests.MyService
+	invoke   func(*_MyServiceProxyInvocation, []any) []any
+}
+
+func (i *_MyServiceProxyInvocation) Invoke(args []any) []any { return i.invoke(i, args) }
+
+func (d *_MyServiceProxyInvocation) invokeNoArgsMethod(args []any) []any {
+	d.delegate.NoArgsMethod()
+	return []any{}
+}
+
+func (d *_MyServiceProxyInvocation) invokeContextMethod(args []any) []any {
+	d.delegate.ContextMethod(args[0].(context.Context))
+	return []any{}
+}
+
+func (d *_MyServiceProxyInvocation) invokePassthroughMethod(args []any) []any {
+	result0 := d.delegate.PassthroughMethod()
+	return []any{result0}
+}
+
+func (d *_MyServiceProxyInvocation) invokeOneArgErrorMethod(args []any) []any {
+	result0 := d.delegate.OneArgErrorMethod()
+	return []any{result0}
+}
+
+func (d *_MyServiceProxyInvocation) invokeTwoArgsErrorMethod(args []any) []any {
+	result0, result1 := d.delegate.TwoArgsErrorMethod(args[0].(context.Context), args[1].(tests.Struct))
+	return []any{result0, result1}
+}
+
+func (d *_MyServiceProxyInvocation) invokeArgsWithComplexImportPathsAndAlias(args []any) []any {
+	d.delegate.ArgsWithComplexImportPathsAndAlias(args[0].(xml.CharData), args[1].(constraint.Expr), args[2].(httptest.ResponseRecorder))
+	return []any{}
+}
+
+func (d *_MyServiceProxyInvocation) invokeHealth(args []any) []any {
+	result0 := d.delegate.Health()
+	return []any{result0}
+}
+
+func NewMyServiceProxy(delegate *tests.MyService, invocationHandler func(method interface {
+	Package() string
+	Receiver() string
+	Name() string
+	Invoke(args []any) []any
+}, args []any) (retVals []any)) *MyServiceProxy {
+	if invocationHandler == nil {
+		invocationHandler = func(method interface {
+			Package() string
+			Receiver() string
+			Name() string
+			Invoke(args []any) []any
+		}, args []any) []any {
+			return method.Invoke(args)
+		}
+	}
+
+	return &MyServiceProxy{
+		delegate:          delegate,
+		invocationHandler: invocationHandler,
+		invocations: []_MyServiceProxyInvocation{
+			{&_MyServiceProxyMethods[0], delegate, (*_MyServiceProxyInvocation).invokeNoArgsMethod},
+			{&_MyServiceProxyMethods[1], delegate, (*_MyServiceProxyInvocation).invokeContextMethod},
+			{&_MyServiceProxyMethods[2], delegate, (*_MyServiceProxyInvocation).invokePassthroughMethod},
+			{&_MyServiceProxyMethods[3], delegate, (*_MyServiceProxyInvocation).invokeOneArgErrorMethod},
+			{&_MyServiceProxyMethods[4], delegate, (*_MyServiceProxyInvocation).invokeTwoArgsErrorMethod},
+			{&_MyServiceProxyMethods[5], delegate, (*_MyServiceProxyInvocation).invokeArgsWithComplexImportPathsAndAlias},
+			{&_MyServiceProxyMethods[6], delegate, (*_MyServiceProxyInvocation).invokeHealth},
+		},
+	}
+}
+
+// _MyServiceMethod describes a proxied method. Descriptors are package-level values, shared by every proxy, so they
+// return copies of their slices and maps, which handlers may modify.
+type _MyServiceMethod struct {
+	methodName   string
+	receiver     string
+	tags         map[string]string
+	paramNames   []string
+	paramTypes   []string
+	resultTypes  []string
+	variadic     bool
+	errorIndex   int
+	contextIndex int
+	position     string
+}
+
+func (m *_MyServiceMethod) Name() string { return m.methodName }
+
+func (m *_MyServiceMethod) Receiver() string { return m.receiver }
+
+func (m *_MyServiceMethod) Package() string { return "tests" }
+
+// Tags returns the tags of the method, set by //proxy:tag directives or a tags file. Invocation handlers can read them
+// with a type assertion to interface { Tags() map[string]string }.
+func (m *_MyServiceMethod) Tags() map[string]string {
+	if m.tags == nil {
+		return nil
+	}
+	tags := make(map[string]string, len(m.tags))
+	for key, value := range m.tags {
+		tags[key] = value
+	}
+	return tags
+}
+
+// ParamNames returns the names of the method's parameters, in the order of args. Unnamed parameters are named after their
+// position, as in p0.
+func (m *_MyServiceMethod) ParamNames() []string { return append([]string(nil), m.paramNames...) }
+
+// ParamTypes returns the declared types of the method's parameters, such as ...string for a variadic parameter, which
+// is passed in args as a slice.
+func (m *_MyServiceMethod) ParamTypes() []string { return append([]string(nil), m.paramTypes...) }
+
+// ResultTypes returns the declared types of the method's results.
+func (m *_MyServiceMethod) ResultTypes() []string { return append([]string(nil), m.resultTypes...) }
+
+// Variadic reports whether the method's last parameter is variadic.
+func (m *_MyServiceMethod) Variadic() bool { return m.variadic }
+
+// ErrorIndex returns the index of the method's trailing error result, or -1 if it doesn't return an error.
+func (m *_MyServiceMethod) ErrorIndex() int { return m.errorIndex }
+
+// ContextIndex returns the index of the method's leading context.Context parameter, or -1 if it doesn't take one.
+func (m *_MyServiceMethod) ContextIndex() int { return m.contextIndex }
+
+// Position returns the file:line of the method's declaration, or an empty string if it's unknown.
+func (m *_MyServiceMethod) Position() string { return m.position }
+
+func _MyServiceCheckResults(results []any, count int, methodName string) {
+	if len(results) != count {
+		panic(fmt.Sprintf("invocation handler returned %d results for %s, expected %d", len(results), methodName, count))
+	}
+}
+
+func _MyServiceResult[T any](results []any, index int, methodName string, typeName string) T {
+	// A nil result, such as a nil error, converts to the zero value of its declared type.
+	result, ok := results[index].(T)
+	if !ok && results[index] != nil {
+		panic(fmt.Sprintf("invocation handler returned %T at index %d for %s, expected %s", results[index], index, methodName, typeName))
+	}
+	return result
+}